@@ -0,0 +1,46 @@
+//go:build atomicfloat_canary
+
+package atomic_float
+
+// Test support only, built with the atomicfloat_canary tag that nothing but
+// TestAsmFrameCanary uses: it calls the assembly in atomic_float_amd64.s
+// through a hand-built ABI0 argument frame, so the test can check that
+// nothing is written past the results. Calls from Go go through a compiler
+// generated wrapper whose frame padding would hide such writes. Assembly
+// cannot live in _test files, hence the tag.
+
+// Indexes into the table of abi0Func.
+const (
+	abi0AddFloat32 = iota
+	abi0StoreFloat32
+	abi0SwapFloat32
+	abi0CompareAndSwapFloat32
+	abi0StoreReleaseFloat32
+	abi0FMAFloat32
+	abi0NegateFloat32
+	abi0AndFloat32
+	abi0OrFloat32
+	abi0IncrementFloat32
+	abi0DecrementFloat32
+	abi0AddFloat64
+	abi0StoreFloat64
+	abi0SwapFloat64
+	abi0CompareAndSwapFloat64
+	abi0StoreReleaseFloat64
+	abi0FMAFloat64
+	abi0NegateFloat64
+	abi0AndFloat64
+	abi0OrFloat64
+	abi0IncrementFloat64
+	abi0DecrementFloat64
+	abi0Count
+)
+
+// abi0Func returns the ABI0 entry point of the assembly function with index
+// i, which must be less than abi0Count.
+func abi0Func(i int) uintptr
+
+// callABI0 calls the ABI0 function fn with its argument frame set to frame
+// and copies the frame back afterwards. The frame must not hold pointers into
+// the goroutine stack.
+func callABI0(fn uintptr, frame *[8]uint64)
@@ -0,0 +1,79 @@
+//go:build atomicfloat_canary
+
+#include "textflag.h"
+#include "funcdata.h"
+
+DATA	abi0funcs<>+0(SB)/8, $·asmAddFloat32(SB)
+DATA	abi0funcs<>+8(SB)/8, $·asmStoreFloat32(SB)
+DATA	abi0funcs<>+16(SB)/8, $·asmSwapFloat32(SB)
+DATA	abi0funcs<>+24(SB)/8, $·asmCompareAndSwapFloat32(SB)
+DATA	abi0funcs<>+32(SB)/8, $·asmStoreReleaseFloat32(SB)
+DATA	abi0funcs<>+40(SB)/8, $·asmFMAFloat32(SB)
+DATA	abi0funcs<>+48(SB)/8, $·asmNegateFloat32(SB)
+DATA	abi0funcs<>+56(SB)/8, $·asmAndFloat32(SB)
+DATA	abi0funcs<>+64(SB)/8, $·asmOrFloat32(SB)
+DATA	abi0funcs<>+72(SB)/8, $·asmIncrementFloat32(SB)
+DATA	abi0funcs<>+80(SB)/8, $·asmDecrementFloat32(SB)
+DATA	abi0funcs<>+88(SB)/8, $·asmAddFloat64(SB)
+DATA	abi0funcs<>+96(SB)/8, $·asmStoreFloat64(SB)
+DATA	abi0funcs<>+104(SB)/8, $·asmSwapFloat64(SB)
+DATA	abi0funcs<>+112(SB)/8, $·asmCompareAndSwapFloat64(SB)
+DATA	abi0funcs<>+120(SB)/8, $·asmStoreReleaseFloat64(SB)
+DATA	abi0funcs<>+128(SB)/8, $·asmFMAFloat64(SB)
+DATA	abi0funcs<>+136(SB)/8, $·asmNegateFloat64(SB)
+DATA	abi0funcs<>+144(SB)/8, $·asmAndFloat64(SB)
+DATA	abi0funcs<>+152(SB)/8, $·asmOrFloat64(SB)
+DATA	abi0funcs<>+160(SB)/8, $·asmIncrementFloat64(SB)
+DATA	abi0funcs<>+168(SB)/8, $·asmDecrementFloat64(SB)
+GLOBL	abi0funcs<>(SB), RODATA, $176
+
+// func abi0Func(i int) uintptr
+TEXT ·abi0Func(SB), NOSPLIT, $0-16
+	MOVQ	i+0(FP), AX
+	LEAQ	abi0funcs<>(SB), BX
+	MOVQ	(BX)(AX*8), AX
+	MOVQ	AX, ret+8(FP)
+	RET
+
+// func callABI0(fn uintptr, frame *[8]uint64)
+// The callee's arguments are the 64 bytes at 0(SP). The pointer it receives
+// is also held by the caller, so the frame needs no pointer map.
+TEXT ·callABI0(SB), $64-16
+	NO_LOCAL_POINTERS
+	MOVQ	frame+8(FP), SI
+	MOVQ	0(SI), AX
+	MOVQ	AX, 0(SP)
+	MOVQ	8(SI), AX
+	MOVQ	AX, 8(SP)
+	MOVQ	16(SI), AX
+	MOVQ	AX, 16(SP)
+	MOVQ	24(SI), AX
+	MOVQ	AX, 24(SP)
+	MOVQ	32(SI), AX
+	MOVQ	AX, 32(SP)
+	MOVQ	40(SI), AX
+	MOVQ	AX, 40(SP)
+	MOVQ	48(SI), AX
+	MOVQ	AX, 48(SP)
+	MOVQ	56(SI), AX
+	MOVQ	AX, 56(SP)
+	MOVQ	fn+0(FP), AX
+	CALL	AX
+	MOVQ	frame+8(FP), SI
+	MOVQ	0(SP), AX
+	MOVQ	AX, 0(SI)
+	MOVQ	8(SP), AX
+	MOVQ	AX, 8(SI)
+	MOVQ	16(SP), AX
+	MOVQ	AX, 16(SI)
+	MOVQ	24(SP), AX
+	MOVQ	AX, 24(SI)
+	MOVQ	32(SP), AX
+	MOVQ	AX, 32(SI)
+	MOVQ	40(SP), AX
+	MOVQ	AX, 40(SI)
+	MOVQ	48(SP), AX
+	MOVQ	AX, 48(SI)
+	MOVQ	56(SP), AX
+	MOVQ	AX, 56(SI)
+	RET
@@ -0,0 +1,148 @@
+//go:build atomicfloat_canary
+
+package atomic_float
+
+import (
+	"math"
+	"testing"
+	"unsafe"
+)
+
+// abi0Slot is a value at byte offset off of an ABI0 argument frame.
+type abi0Slot struct {
+	off, width int
+	val        uint64
+}
+
+// TestAsmFrameCanary calls every assembly function with an argument frame
+// filled with canary bytes and checks that only the result slot changes, and
+// that the result and memory match the same call made from Go.
+func TestAsmFrameCanary(t *testing.T) {
+	const canary = 0xa5
+	f32 := func(v float32) uint64 { return uint64(math.Float32bits(v)) }
+	f64 := math.Float64bits
+	tests := []struct {
+		name string
+		fn   int
+		size int // argument frame size, the $0-N of the TEXT directive
+		args []abi0Slot
+		ret  abi0Slot // width is 0 without a result
+		ref  func(p unsafe.Pointer) uint64
+	}{
+		{"AddFloat32", abi0AddFloat32, 20, []abi0Slot{{8, 4, f32(2.25)}}, abi0Slot{16, 4, 0},
+			func(p unsafe.Pointer) uint64 { return f32(asmAddFloat32((*float32)(p), 2.25)) }},
+		{"StoreFloat32", abi0StoreFloat32, 12, []abi0Slot{{8, 4, f32(2.25)}}, abi0Slot{},
+			func(p unsafe.Pointer) uint64 { asmStoreFloat32((*float32)(p), 2.25); return 0 }},
+		{"SwapFloat32", abi0SwapFloat32, 20, []abi0Slot{{8, 4, f32(2.25)}}, abi0Slot{16, 4, 0},
+			func(p unsafe.Pointer) uint64 { return f32(asmSwapFloat32((*float32)(p), 2.25)) }},
+		{"CompareAndSwapFloat32", abi0CompareAndSwapFloat32, 17, []abi0Slot{{8, 4, f32(1.5)}, {12, 4, f32(2.25)}}, abi0Slot{16, 1, 0},
+			func(p unsafe.Pointer) uint64 {
+				if asmCompareAndSwapFloat32((*float32)(p), 1.5, 2.25) {
+					return 1
+				}
+				return 0
+			}},
+		{"StoreReleaseFloat32", abi0StoreReleaseFloat32, 12, []abi0Slot{{8, 4, f32(2.25)}}, abi0Slot{},
+			func(p unsafe.Pointer) uint64 { asmStoreReleaseFloat32((*float32)(p), 2.25); return 0 }},
+		{"FMAFloat32", abi0FMAFloat32, 20, []abi0Slot{{8, 4, f32(3)}, {12, 4, f32(0.5)}}, abi0Slot{16, 4, 0},
+			func(p unsafe.Pointer) uint64 { return f32(asmFMAFloat32((*float32)(p), 3, 0.5)) }},
+		{"NegateFloat32", abi0NegateFloat32, 12, nil, abi0Slot{8, 4, 0},
+			func(p unsafe.Pointer) uint64 { return f32(asmNegateFloat32((*float32)(p))) }},
+		{"AndFloat32", abi0AndFloat32, 20, []abi0Slot{{8, 4, 1<<31 - 1}}, abi0Slot{16, 4, 0},
+			func(p unsafe.Pointer) uint64 { return f32(asmAndFloat32((*float32)(p), 1<<31-1)) }},
+		{"OrFloat32", abi0OrFloat32, 20, []abi0Slot{{8, 4, 1 << 31}}, abi0Slot{16, 4, 0},
+			func(p unsafe.Pointer) uint64 { return f32(asmOrFloat32((*float32)(p), 1<<31)) }},
+		{"IncrementFloat32", abi0IncrementFloat32, 12, nil, abi0Slot{8, 4, 0},
+			func(p unsafe.Pointer) uint64 { return f32(asmIncrementFloat32((*float32)(p))) }},
+		{"DecrementFloat32", abi0DecrementFloat32, 12, nil, abi0Slot{8, 4, 0},
+			func(p unsafe.Pointer) uint64 { return f32(asmDecrementFloat32((*float32)(p))) }},
+
+		{"AddFloat64", abi0AddFloat64, 24, []abi0Slot{{8, 8, f64(2.25)}}, abi0Slot{16, 8, 0},
+			func(p unsafe.Pointer) uint64 { return f64(asmAddFloat64((*float64)(p), 2.25)) }},
+		{"StoreFloat64", abi0StoreFloat64, 16, []abi0Slot{{8, 8, f64(2.25)}}, abi0Slot{},
+			func(p unsafe.Pointer) uint64 { asmStoreFloat64((*float64)(p), 2.25); return 0 }},
+		{"SwapFloat64", abi0SwapFloat64, 24, []abi0Slot{{8, 8, f64(2.25)}}, abi0Slot{16, 8, 0},
+			func(p unsafe.Pointer) uint64 { return f64(asmSwapFloat64((*float64)(p), 2.25)) }},
+		{"CompareAndSwapFloat64", abi0CompareAndSwapFloat64, 25, []abi0Slot{{8, 8, f64(1.5)}, {16, 8, f64(2.25)}}, abi0Slot{24, 1, 0},
+			func(p unsafe.Pointer) uint64 {
+				if asmCompareAndSwapFloat64((*float64)(p), 1.5, 2.25) {
+					return 1
+				}
+				return 0
+			}},
+		{"StoreReleaseFloat64", abi0StoreReleaseFloat64, 16, []abi0Slot{{8, 8, f64(2.25)}}, abi0Slot{},
+			func(p unsafe.Pointer) uint64 { asmStoreReleaseFloat64((*float64)(p), 2.25); return 0 }},
+		{"FMAFloat64", abi0FMAFloat64, 32, []abi0Slot{{8, 8, f64(3)}, {16, 8, f64(0.5)}}, abi0Slot{24, 8, 0},
+			func(p unsafe.Pointer) uint64 { return f64(asmFMAFloat64((*float64)(p), 3, 0.5)) }},
+		{"NegateFloat64", abi0NegateFloat64, 16, nil, abi0Slot{8, 8, 0},
+			func(p unsafe.Pointer) uint64 { return f64(asmNegateFloat64((*float64)(p))) }},
+		{"AndFloat64", abi0AndFloat64, 24, []abi0Slot{{8, 8, 1<<63 - 1}}, abi0Slot{16, 8, 0},
+			func(p unsafe.Pointer) uint64 { return f64(asmAndFloat64((*float64)(p), 1<<63-1)) }},
+		{"OrFloat64", abi0OrFloat64, 24, []abi0Slot{{8, 8, 1 << 63}}, abi0Slot{16, 8, 0},
+			func(p unsafe.Pointer) uint64 { return f64(asmOrFloat64((*float64)(p), 1<<63)) }},
+		{"IncrementFloat64", abi0IncrementFloat64, 16, nil, abi0Slot{8, 8, 0},
+			func(p unsafe.Pointer) uint64 { return f64(asmIncrementFloat64((*float64)(p))) }},
+		{"DecrementFloat64", abi0DecrementFloat64, 16, nil, abi0Slot{8, 8, 0},
+			func(p unsafe.Pointer) uint64 { return f64(asmDecrementFloat64((*float64)(p))) }},
+	}
+	if len(tests) != abi0Count {
+		t.Fatalf("Expected %v cases, got %v", abi0Count, len(tests))
+	}
+
+	for _, tt := range tests {
+		if (tt.fn == abi0FMAFloat32 || tt.fn == abi0FMAFloat64) && !x86HasFMA {
+			continue
+		}
+		// Both cells hold 1.5 in the format the function expects, float32
+		// ones followed by canary bytes to catch too wide stores.
+		cell, want := new(uint64), new(uint64)
+		if tt.fn >= abi0AddFloat64 {
+			*cell = f64(1.5)
+		} else {
+			*cell = 0xa5a5a5a5<<32 | f32(1.5)
+		}
+		*want = *cell
+		wantRet := tt.ref(unsafe.Pointer(want))
+
+		var frame [8]uint64
+		b := (*[64]byte)(unsafe.Pointer(&frame))
+		for i := range b {
+			b[i] = canary
+		}
+		*(*uintptr)(unsafe.Pointer(&b[0])) = uintptr(unsafe.Pointer(cell))
+		for _, a := range tt.args {
+			putSlot(b[a.off:a.off+a.width], a.val)
+		}
+		before := *b
+		callABI0(abi0Func(tt.fn), &frame)
+
+		for i := 8; i < len(b); i++ {
+			inRet := i >= tt.ret.off && i < tt.ret.off+tt.ret.width
+			if !inRet && b[i] != before[i] {
+				t.Errorf("%s: frame byte %d changed from %#x to %#x", tt.name, i, before[i], b[i])
+			}
+		}
+		if tt.ret.width != 0 {
+			if result := getSlot(b[tt.ret.off : tt.ret.off+tt.ret.width]); result != wantRet {
+				t.Errorf("%s: Expected result %#x, got %#x", tt.name, wantRet, result)
+			}
+		}
+		if *cell != *want {
+			t.Errorf("%s: Expected memory %#x, got %#x", tt.name, *want, *cell)
+		}
+	}
+}
+
+func putSlot(b []byte, v uint64) {
+	for i := range b {
+		b[i] = byte(v >> (8 * i))
+	}
+}
+
+func getSlot(b []byte) uint64 {
+	var v uint64
+	for i := range b {
+		v |= uint64(b[i]) << (8 * i)
+	}
+	return v
+}
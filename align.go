@@ -0,0 +1,86 @@
+package atomic_float
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"unsafe"
+)
+
+// 64-bit atomic operations need an 8-byte aligned address. On 32-bit
+// platforms a float64 is only 4-byte aligned, so a float64 struct field can
+// be misaligned and operations on it silently lose atomicity; on 64-bit
+// platforms a misaligned raw pointer can straddle a cache line. Float64 is
+// always aligned, the raw pointer functions are not.
+//
+// Building with the atomicfloat_debug tag makes every function taking a
+// *float64 panic on a misaligned pointer. Without it the check compiles away.
+
+// IsAligned64 reports whether ptr is 8-byte aligned, as 64-bit atomic
+// operations require.
+func IsAligned64(ptr *float64) bool {
+	return uintptr(unsafe.Pointer(ptr))%8 == 0
+}
+
+func checkAligned64(ptr *float64) {
+	if debugAlignment && !IsAligned64(ptr) {
+		panicMisaligned(ptr)
+	}
+}
+
+//go:noinline
+func panicMisaligned(ptr *float64) {
+	panic(fmt.Sprintf("atomic_float: unaligned 64-bit atomic operation on *float64 %p: it needs 8-byte alignment, "+
+		"use Float64 or move the field to the start of the struct", ptr))
+}
+
+// MisalignedFloat64Fields returns the paths, such as "Stats.Sum" or
+// "Buckets[1].Total", of the float64 fields in typ that are not 8-byte
+// aligned on the architecture the program is built for, assuming a value of
+// typ starts 8-byte aligned, as allocated values do. It looks into nested
+// structs and arrays but not behind pointers, slices or maps. Use it in tests
+// run with GOARCH=386 or arm to find fields unsafe for the raw pointer
+// functions.
+func MisalignedFloat64Fields(typ reflect.Type) []string {
+	var bad []string
+	walkFloat64Fields(typ, 0, "", &bad)
+	return bad
+}
+
+func walkFloat64Fields(typ reflect.Type, off uintptr, path string, bad *[]string) {
+	switch typ.Kind() {
+	case reflect.Float64:
+		if off%8 != 0 {
+			*bad = append(*bad, path)
+		}
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			name := f.Name
+			if path != "" {
+				name = path + "." + name
+			}
+			walkFloat64Fields(f.Type, off+f.Offset, name, bad)
+		}
+	case reflect.Array:
+		// Elements repeat every Size bytes, so the first two show every
+		// offset modulo 8 that an element can start at when Size%8 is 4.
+		n := min(typ.Len(), 2)
+		for i := 0; i < n; i++ {
+			walkFloat64Fields(typ.Elem(), off+uintptr(i)*typ.Elem().Size(), path+"["+strconv.Itoa(i)+"]", bad)
+		}
+	}
+}
+
+// CheckAlignment reports through t, typically a *testing.T, every float64
+// field of the type of v that MisalignedFloat64Fields finds.
+func CheckAlignment(t interface {
+	Helper()
+	Errorf(format string, args ...any)
+}, v any) {
+	t.Helper()
+	typ := reflect.TypeOf(v)
+	for _, path := range MisalignedFloat64Fields(typ) {
+		t.Errorf("%v: float64 field %s is not 8-byte aligned", typ, path)
+	}
+}
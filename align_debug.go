@@ -0,0 +1,6 @@
+//go:build atomicfloat_debug
+
+package atomic_float
+
+// debugAlignment enables the alignment checks of checkAligned64.
+const debugAlignment = true
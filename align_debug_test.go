@@ -0,0 +1,55 @@
+//go:build atomicfloat_debug
+
+package atomic_float
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+// TestDebugMisalignedPanics checks that with the atomicfloat_debug tag every
+// function taking a *float64 panics on a misaligned pointer.
+func TestDebugMisalignedPanics(t *testing.T) {
+	buf := make([]uint64, 3)
+	p := (*float64)(unsafe.Add(unsafe.Pointer(&buf[0]), 4))
+	ops := map[string]func(){
+		"Load":           func() { LoadFloat64(p) },
+		"Add":            func() { AddFloat64(p, 1) },
+		"Store":          func() { StoreFloat64(p, 1) },
+		"Swap":           func() { SwapFloat64(p, 1) },
+		"CompareAndSwap": func() { CompareAndSwapFloat64(p, 0, 1) },
+		"LoadAcquire":    func() { LoadAcquireFloat64(p) },
+		"LoadRelaxed":    func() { LoadRelaxedFloat64(p) },
+		"StoreRelease":   func() { StoreReleaseFloat64(p, 1) },
+		"StoreRelaxed":   func() { StoreRelaxedFloat64(p, 1) },
+		"FMA":            func() { FMAFloat64(p, 1, 1) },
+		"AddIfWithin":    func() { AddIfWithinFloat64(p, 1, 0, 2) },
+		"AddClamped":     func() { AddClampedFloat64(p, 1, 0, 2) },
+		"Negate":         func() { NegateFloat64(p) },
+		"Abs":            func() { AbsFloat64(p) },
+		"SetSign":        func() { SetSignFloat64(p, true) },
+		"Increment":      func() { IncrementFloat64(p) },
+		"Decrement":      func() { DecrementFloat64(p) },
+	}
+	for name, op := range ops {
+		func() {
+			defer func() {
+				if r := fmt.Sprint(recover()); !strings.Contains(r, "unaligned 64-bit atomic operation on *float64") {
+					t.Errorf("%s: Expected a misaligned panic, got %v", name, r)
+				}
+			}()
+			op()
+		}()
+	}
+	if buf[0] != 0 || buf[1] != 0 || buf[2] != 0 {
+		t.Errorf("Expected memory to be unchanged, got %#x", buf)
+	}
+
+	var f Float64
+	f.Add(1)
+	if result := f.Load(); result != 1 {
+		t.Errorf("Expected %v, got %v", 1, result)
+	}
+}
@@ -0,0 +1,81 @@
+package atomic_float
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+func TestIsAligned64(t *testing.T) {
+	buf := make([]uint64, 2)
+	base := unsafe.Pointer(&buf[0])
+	for off, want := range map[uintptr]bool{0: true, 4: false, 8: true, 1: false} {
+		p := (*float64)(unsafe.Add(base, off))
+		if result := IsAligned64(p); result != want {
+			t.Errorf("Offset %d: expected %v, got %v", off, want, result)
+		}
+	}
+	if !IsAligned64(&NewFloat64(0).v) {
+		t.Errorf("Expected Float64 to be aligned")
+	}
+}
+
+type alignInner struct {
+	Flag  bool
+	Total float64
+}
+
+type alignOuter struct {
+	Count   int32
+	Sum     float64
+	Safe    Float64
+	Inner   alignInner
+	Buckets [3]struct {
+		N   int32
+		Val float64
+	}
+	Ptr *alignInner
+}
+
+// TestMisalignedFloat64Fields checks the walker against the layout of the
+// architecture the test runs on: nothing is misaligned where float64 is
+// 8-byte aligned, and exactly the fields after a 4-byte offset are on 32-bit.
+func TestMisalignedFloat64Fields(t *testing.T) {
+	var want []string
+	if unsafe.Alignof(float64(0)) == 4 {
+		// Sum at 4; Safe is padded to 8 by align64 so Inner starts at 24
+		// and Inner.Total at 28; Buckets start at 36, element 0 holds its
+		// float64 at 40 and element 1 at 52.
+		want = []string{"Sum", "Inner.Total", "Buckets[1].Val"}
+	}
+	result := MisalignedFloat64Fields(reflect.TypeOf(alignOuter{}))
+	if fmt.Sprint(result) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, result)
+	}
+	if result := MisalignedFloat64Fields(reflect.TypeOf(Float64{})); len(result) != 0 {
+		t.Errorf("Expected no misaligned fields in Float64, got %v", result)
+	}
+}
+
+// recordingT collects the errors CheckAlignment reports.
+type recordingT struct{ errors []string }
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestCheckAlignment(t *testing.T) {
+	var r recordingT
+	CheckAlignment(&r, alignOuter{})
+	want := len(MisalignedFloat64Fields(reflect.TypeOf(alignOuter{})))
+	if len(r.errors) != want {
+		t.Errorf("Expected %v errors, got %v", want, r.errors)
+	}
+
+	// Types used with the raw pointer functions in this package.
+	CheckAlignment(t, PaddedFloat64{})
+	CheckAlignment(t, NullFloat64{})
+}
@@ -1,39 +1,165 @@
 package atomic_float
 
+import "math"
+
 // See src/runtime/internal/atomic/types.go
 
-//go:nosplit
-//go:noinline
-func LoadFloat32(ptr *float32) float32 {
-	return *ptr
+// casFMAFloat32 implements FMAFloat32 with a CompareAndSwap loop.
+func casFMAFloat32(ptr *float32, a, b float32) float32 {
+	for {
+		old := LoadFloat32(ptr)
+		new := fmaFloat32(a, b, old)
+		if CompareAndSwapFloat32(ptr, old, new) {
+			return new
+		}
+	}
 }
 
-//go:noescape
-func AddFloat32(ptr *float32, delta float32) float32
+// fmaFloat32 returns a*b + c rounded once to the nearest float32, ties to
+// even. The product of two float32 values is exact in float64, so only the
+// sum s = a*b + c is rounded in float64, and rounding s again to float32 is
+// wrong only when s lands exactly halfway between two float32 values while
+// the exact sum does not. The rounding error of s, computed exactly with
+// TwoSum, tells which way the exact sum lies.
+func fmaFloat32(a, b, c float32) float32 {
+	xy := float64(a) * float64(b)
+	s := xy + float64(c)
+	f := float32(s)
+	if float64(f) == s || math.IsNaN(s) || math.IsInf(s, 0) {
+		return f
+	}
+	t := s - xy
+	err := (xy - (s - t)) + (float64(c) - t)
+	if err == 0 {
+		return f
+	}
+	// g is the float32 neighbor of f on the other side of s. An infinite f
+	// or g stands for 2^128, the next value on the float32 grid.
+	g := math.Nextafter32(f, float32(math.Copysign(math.Inf(1), s-float64(f))))
+	fv, gv := float64(f), float64(g)
+	if math.IsInf(fv, 0) {
+		fv = math.Copysign(0x1p128, fv)
+	}
+	if math.IsInf(gv, 0) {
+		gv = math.Copysign(0x1p128, gv)
+	}
+	if (fv+gv)/2 != s || (err > 0) != (gv > fv) {
+		return f
+	}
+	return g
+}
 
-//go:noescape
-func StoreFloat32(ptr *float32, delta float32)
+// AddIfWithinFloat32 atomically adds delta to *ptr only if the result lies in
+// [lo, hi]. It returns the resulting value and true, or the unchanged current
+// value and false.
+func AddIfWithinFloat32(ptr *float32, delta, lo, hi float32) (new float32, ok bool) {
+	for {
+		old := LoadFloat32(ptr)
+		new := old + delta
+		if !(new >= lo && new <= hi) {
+			return old, false
+		}
+		if CompareAndSwapFloat32(ptr, old, new) {
+			return new, true
+		}
+	}
+}
 
-//go:noescape
-func SwapFloat32(ptr *float32, delta float32) float32
+// AddClampedFloat32 atomically adds delta to *ptr, saturating the result to
+// [lo, hi], and returns the new value. If old+delta is NaN, because delta or
+// *ptr is NaN or infinities of opposite sign meet, *ptr is left unchanged and
+// its value is returned. It panics if lo > hi or either bound is NaN.
+func AddClampedFloat32(ptr *float32, delta, lo, hi float32) float32 {
+	if !(lo <= hi) {
+		panic("atomic_float: AddClamped needs lo <= hi")
+	}
+	for {
+		old := LoadFloat32(ptr)
+		new := old + delta
+		if new != new {
+			return old
+		}
+		new = min(max(new, lo), hi)
+		if CompareAndSwapFloat32(ptr, old, new) {
+			return new
+		}
+	}
+}
 
-//go:noescape
-func CompareAndSwapFloat32(ptr *float32, old float32, new float32) bool
+// AbsFloat32 atomically clears the sign bit of *ptr and returns the previous
+// value.
+func AbsFloat32(ptr *float32) (old float32) {
+	return andFloat32(ptr, 1<<31-1)
+}
 
-//go:nosplit
-//go:noinline
-func LoadFloat64(ptr *float64) float64 {
-	return *ptr
+// SetSignFloat32 atomically sets the sign bit of *ptr if neg is true and
+// clears it otherwise, and returns the previous value.
+func SetSignFloat32(ptr *float32, neg bool) (old float32) {
+	if neg {
+		return orFloat32(ptr, 1<<31)
+	}
+	return andFloat32(ptr, 1<<31-1)
 }
 
-//go:noescape
-func AddFloat64(ptr *float64, delta float64) float64
+// casFMAFloat64 implements FMAFloat64 with a CompareAndSwap loop.
+func casFMAFloat64(ptr *float64, a, b float64) float64 {
+	for {
+		old := LoadFloat64(ptr)
+		new := math.FMA(a, b, old)
+		if CompareAndSwapFloat64(ptr, old, new) {
+			return new
+		}
+	}
+}
 
-//go:noescape
-func StoreFloat64(ptr *float64, delta float64)
+// AddIfWithinFloat64 atomically adds delta to *ptr only if the result lies in
+// [lo, hi]. It returns the resulting value and true, or the unchanged current
+// value and false.
+func AddIfWithinFloat64(ptr *float64, delta, lo, hi float64) (new float64, ok bool) {
+	for {
+		old := LoadFloat64(ptr)
+		new := old + delta
+		if !(new >= lo && new <= hi) {
+			return old, false
+		}
+		if CompareAndSwapFloat64(ptr, old, new) {
+			return new, true
+		}
+	}
+}
 
-//go:noescape
-func SwapFloat64(ptr *float64, delta float64) float64
+// AddClampedFloat64 atomically adds delta to *ptr, saturating the result to
+// [lo, hi], and returns the new value. If old+delta is NaN, because delta or
+// *ptr is NaN or infinities of opposite sign meet, *ptr is left unchanged and
+// its value is returned. It panics if lo > hi or either bound is NaN.
+func AddClampedFloat64(ptr *float64, delta, lo, hi float64) float64 {
+	if !(lo <= hi) {
+		panic("atomic_float: AddClamped needs lo <= hi")
+	}
+	for {
+		old := LoadFloat64(ptr)
+		new := old + delta
+		if new != new {
+			return old
+		}
+		new = min(max(new, lo), hi)
+		if CompareAndSwapFloat64(ptr, old, new) {
+			return new
+		}
+	}
+}
 
-//go:noescape
-func CompareAndSwapFloat64(ptr *float64, old float64, new float64) bool
+// AbsFloat64 atomically clears the sign bit of *ptr and returns the previous
+// value.
+func AbsFloat64(ptr *float64) (old float64) {
+	return andFloat64(ptr, 1<<63-1)
+}
+
+// SetSignFloat64 atomically sets the sign bit of *ptr if neg is true and
+// clears it otherwise, and returns the previous value.
+func SetSignFloat64(ptr *float64, neg bool) (old float64) {
+	if neg {
+		return orFloat64(ptr, 1<<63)
+	}
+	return andFloat64(ptr, 1<<63-1)
+}
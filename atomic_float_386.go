@@ -0,0 +1,46 @@
+package atomic_float
+
+// Assembly implementation, see atomic_float_386.s. It backs the exported
+// functions when built with the atomicfloat_asm tag (see atomic_float_asm.go)
+// and is always compiled on 386 so the tests can compare it with the default
+// sync/atomic implementation. The float arithmetic requires SSE2.
+//
+// A float64 is only accessed atomically at an 8-byte aligned address, which
+// Float64 guarantees with align64. The 64-bit functions panic on misaligned
+// raw pointers instead of silently tearing.
+
+// panicUnaligned is called from the assembly, see
+// src/internal/runtime/atomic/unaligned.go.
+func panicUnaligned() {
+	panic("atomic_float: unaligned 64-bit atomic operation")
+}
+
+//go:noescape
+func asmLoadFloat32(ptr *float32) float32
+
+//go:noescape
+func asmAddFloat32(ptr *float32, delta float32) float32
+
+//go:noescape
+func asmStoreFloat32(ptr *float32, val float32)
+
+//go:noescape
+func asmSwapFloat32(ptr *float32, new float32) float32
+
+//go:noescape
+func asmCompareAndSwapFloat32(ptr *float32, old float32, new float32) bool
+
+//go:noescape
+func asmLoadFloat64(ptr *float64) float64
+
+//go:noescape
+func asmAddFloat64(ptr *float64, delta float64) float64
+
+//go:noescape
+func asmStoreFloat64(ptr *float64, val float64)
+
+//go:noescape
+func asmSwapFloat64(ptr *float64, new float64) float64
+
+//go:noescape
+func asmCompareAndSwapFloat64(ptr *float64, old float64, new float64) bool
@@ -0,0 +1,180 @@
+#include "textflag.h"
+#include "funcdata.h"
+
+// See src/internal/runtime/atomic/atomic_386.s
+
+// float32 asmLoadFloat32(ptr *float32)
+// Aligned 4-byte loads are atomic.
+TEXT ·asmLoadFloat32(SB), NOSPLIT, $0-8
+	MOVL	ptr+0(FP), AX
+	MOVL	0(AX), AX
+	MOVL	AX, ret+4(FP)
+	RET
+
+// float32 asmAddFloat32(ptr *float32, delta float32)
+// Atomically:
+//	*ptr += delta;
+//	return *ptr;
+TEXT ·asmAddFloat32(SB), NOSPLIT, $0-12
+	MOVL	ptr+0(FP), BX
+	MOVSS	delta+4(FP), X0
+loop:
+	MOVL	0(BX), AX
+	MOVL	AX, X1
+	ADDSS	X0, X1
+	MOVL	X1, CX
+	LOCK
+	CMPXCHGL	CX, 0(BX)
+	JNE	loop
+	MOVL	CX, ret+8(FP)
+	RET
+
+// asmStoreFloat32(ptr *float32, val float32)
+// Atomically:
+//	*ptr = val;
+TEXT ·asmStoreFloat32(SB), NOSPLIT, $0-8
+	MOVL	ptr+0(FP), BX
+	MOVL	val+4(FP), AX
+	XCHGL	AX, 0(BX)
+	RET
+
+// float32 asmSwapFloat32(ptr *float32, new float32)
+// Atomically:
+//	old := *ptr;
+//	*ptr = new;
+//	return old;
+TEXT ·asmSwapFloat32(SB), NOSPLIT, $0-12
+	MOVL	ptr+0(FP), BX
+	MOVL	new+4(FP), AX
+	XCHGL	AX, 0(BX)
+	MOVL	AX, ret+8(FP)
+	RET
+
+// bool asmCompareAndSwapFloat32(ptr *float32, old float32, new float32)
+// Compares bit patterns, not float values.
+// Atomically:
+//	if(*ptr == old){
+//		*ptr = new;
+//		return 1;
+//	} else
+//		return 0;
+TEXT ·asmCompareAndSwapFloat32(SB), NOSPLIT, $0-13
+	MOVL	ptr+0(FP), BX
+	MOVL	old+4(FP), AX
+	MOVL	new+8(FP), CX
+	LOCK
+	CMPXCHGL	CX, 0(BX)
+	SETEQ	ret+12(FP)
+	RET
+
+// float64 asmLoadFloat64(ptr *float64)
+// Aligned 8-byte SSE loads are atomic.
+TEXT ·asmLoadFloat64(SB), NOSPLIT, $0-12
+	NO_LOCAL_POINTERS
+	MOVL	ptr+0(FP), AX
+	TESTL	$7, AX
+	JZ	2(PC)
+	CALL	·panicUnaligned(SB)
+	MOVSD	0(AX), X0
+	MOVSD	X0, ret+4(FP)
+	RET
+
+// float64 asmAddFloat64(ptr *float64, delta float64)
+// Atomically:
+//	*ptr += delta;
+//	return *ptr;
+TEXT ·asmAddFloat64(SB), NOSPLIT, $0-20
+	NO_LOCAL_POINTERS
+	// no FP -> can use BP
+	MOVL	ptr+0(FP), BP
+	TESTL	$7, BP
+	JZ	2(PC)
+	CALL	·panicUnaligned(SB)
+	MOVSD	delta+4(FP), X0
+	// DX:AX = *ptr, a torn read only costs a CMPXCHG8B retry
+	MOVL	0(BP), AX
+	MOVL	4(BP), DX
+addloop:
+	// CX:BX = float64(DX:AX) + delta
+	MOVL	AX, X1
+	MOVL	DX, X2
+	PUNPCKLLQ	X2, X1
+	ADDSD	X0, X1
+	MOVL	X1, BX
+	PSRLQ	$32, X1
+	MOVL	X1, CX
+	// if *ptr == DX:AX {
+	//	*ptr = CX:BX
+	// } else {
+	//	DX:AX = *ptr
+	// }
+	LOCK
+	CMPXCHG8B	0(BP)
+	JNZ	addloop
+	MOVL	BX, ret_lo+12(FP)
+	MOVL	CX, ret_hi+16(FP)
+	RET
+
+// asmStoreFloat64(ptr *float64, val float64)
+// Atomically:
+//	*ptr = val;
+TEXT ·asmStoreFloat64(SB), NOSPLIT, $0-12
+	NO_LOCAL_POINTERS
+	MOVL	ptr+0(FP), AX
+	TESTL	$7, AX
+	JZ	2(PC)
+	CALL	·panicUnaligned(SB)
+	MOVSD	val+4(FP), X0
+	MOVSD	X0, 0(AX)
+	// A locked no-op provides the StoreLoad fence a sequentially
+	// consistent store needs.
+	XORL	AX, AX
+	LOCK
+	XADDL	AX, (SP)
+	RET
+
+// float64 asmSwapFloat64(ptr *float64, new float64)
+// Atomically:
+//	old := *ptr;
+//	*ptr = new;
+//	return old;
+TEXT ·asmSwapFloat64(SB), NOSPLIT, $0-20
+	NO_LOCAL_POINTERS
+	MOVL	ptr+0(FP), BP
+	TESTL	$7, BP
+	JZ	2(PC)
+	CALL	·panicUnaligned(SB)
+	MOVL	new_lo+4(FP), BX
+	MOVL	new_hi+8(FP), CX
+	MOVL	0(BP), AX
+	MOVL	4(BP), DX
+swaploop:
+	LOCK
+	CMPXCHG8B	0(BP)
+	JNZ	swaploop
+	MOVL	AX, ret_lo+12(FP)
+	MOVL	DX, ret_hi+16(FP)
+	RET
+
+// bool asmCompareAndSwapFloat64(ptr *float64, old float64, new float64)
+// Compares bit patterns, not float values.
+// Atomically:
+//	if(*ptr == old){
+//		*ptr = new;
+//		return 1;
+//	} else
+//		return 0;
+TEXT ·asmCompareAndSwapFloat64(SB), NOSPLIT, $0-21
+	NO_LOCAL_POINTERS
+	MOVL	ptr+0(FP), BP
+	TESTL	$7, BP
+	JZ	2(PC)
+	CALL	·panicUnaligned(SB)
+	MOVL	old_lo+4(FP), AX
+	MOVL	old_hi+8(FP), DX
+	MOVL	new_lo+12(FP), BX
+	MOVL	new_hi+16(FP), CX
+	LOCK
+	CMPXCHG8B	0(BP)
+	SETEQ	ret+20(FP)
+	RET
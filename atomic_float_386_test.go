@@ -0,0 +1,54 @@
+package atomic_float
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+// TestUnalignedFloat64Panics checks that every 64-bit operation panics on a
+// pointer that is not 8-byte aligned instead of tearing the value.
+func TestUnalignedFloat64Panics(t *testing.T) {
+	buf := make([]uint64, 2)
+	p := (*float64)(unsafe.Add(unsafe.Pointer(&buf[0]), 4))
+	ops := map[string]func(){
+		"asmLoadFloat64":           func() { asmLoadFloat64(p) },
+		"asmAddFloat64":            func() { asmAddFloat64(p, 1) },
+		"asmStoreFloat64":          func() { asmStoreFloat64(p, 1) },
+		"asmSwapFloat64":           func() { asmSwapFloat64(p, 1) },
+		"asmCompareAndSwapFloat64": func() { asmCompareAndSwapFloat64(p, 0, 1) },
+		"LoadFloat64":              func() { LoadFloat64(p) },
+		"AddFloat64":               func() { AddFloat64(p, 1) },
+		"StoreFloat64":             func() { StoreFloat64(p, 1) },
+		"SwapFloat64":              func() { SwapFloat64(p, 1) },
+		"CompareAndSwapFloat64":    func() { CompareAndSwapFloat64(p, 0, 1) },
+	}
+	for name, op := range ops {
+		func() {
+			defer func() {
+				if r := fmt.Sprint(recover()); !strings.Contains(r, "unaligned 64-bit atomic operation") {
+					t.Errorf("%s: Expected an unaligned panic, got %v", name, r)
+				}
+			}()
+			op()
+		}()
+	}
+	if buf[0] != 0 || buf[1] != 0 {
+		t.Errorf("Expected memory to be unchanged, got %#x %#x", buf[0], buf[1])
+	}
+}
+
+// TestFloat64Aligned checks that align64 keeps Float64 8-byte aligned after
+// a 4-byte field, where a plain float64 would only be 4-byte aligned.
+func TestFloat64Aligned(t *testing.T) {
+	var s [4]struct {
+		a uint32
+		f Float64
+	}
+	for i := range s {
+		if addr := uintptr(unsafe.Pointer(&s[i].f.v)); addr%8 != 0 {
+			t.Errorf("Expected 8-byte alignment, got address %#x", addr)
+		}
+	}
+}
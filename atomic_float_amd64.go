@@ -0,0 +1,98 @@
+package atomic_float
+
+// Assembly implementation, see atomic_float_amd64.s. It backs the exported
+// functions when built with the atomicfloat_asm tag (see atomic_float_asm.go)
+// and is always compiled on amd64 so the benchmarks can compare it with the
+// default sync/atomic implementation.
+//
+// amd64 is a TSO architecture: every load already has acquire semantics and
+// every store has release semantics, so only the sequentially consistent
+// Store needs a locked instruction.
+
+//go:nosplit
+//go:noinline
+func asmLoadFloat32(ptr *float32) float32 {
+	return *ptr
+}
+
+//go:noescape
+func asmAddFloat32(ptr *float32, delta float32) float32
+
+//go:noescape
+func asmStoreFloat32(ptr *float32, val float32)
+
+//go:noescape
+func asmSwapFloat32(ptr *float32, new float32) float32
+
+//go:noescape
+func asmCompareAndSwapFloat32(ptr *float32, old float32, new float32) bool
+
+//go:noescape
+func asmStoreReleaseFloat32(ptr *float32, val float32)
+
+//go:noescape
+func asmStoreRelaxedFloat32(ptr *float32, val float32)
+
+// asmFMAFloat32 requires FMA3, see x86HasFMA.
+//
+//go:noescape
+func asmFMAFloat32(ptr *float32, a, b float32) float32
+
+//go:noescape
+func asmNegateFloat32(ptr *float32) (old float32)
+
+//go:noescape
+func asmAndFloat32(ptr *float32, mask uint32) (old float32)
+
+//go:noescape
+func asmOrFloat32(ptr *float32, mask uint32) (old float32)
+
+//go:noescape
+func asmIncrementFloat32(ptr *float32) (new float32)
+
+//go:noescape
+func asmDecrementFloat32(ptr *float32) (new float32)
+
+//go:nosplit
+//go:noinline
+func asmLoadFloat64(ptr *float64) float64 {
+	return *ptr
+}
+
+//go:noescape
+func asmAddFloat64(ptr *float64, delta float64) float64
+
+//go:noescape
+func asmStoreFloat64(ptr *float64, val float64)
+
+//go:noescape
+func asmSwapFloat64(ptr *float64, new float64) float64
+
+//go:noescape
+func asmCompareAndSwapFloat64(ptr *float64, old float64, new float64) bool
+
+//go:noescape
+func asmStoreReleaseFloat64(ptr *float64, val float64)
+
+//go:noescape
+func asmStoreRelaxedFloat64(ptr *float64, val float64)
+
+// asmFMAFloat64 requires FMA3, see x86HasFMA.
+//
+//go:noescape
+func asmFMAFloat64(ptr *float64, a, b float64) float64
+
+//go:noescape
+func asmNegateFloat64(ptr *float64) (old float64)
+
+//go:noescape
+func asmAndFloat64(ptr *float64, mask uint64) (old float64)
+
+//go:noescape
+func asmOrFloat64(ptr *float64, mask uint64) (old float64)
+
+//go:noescape
+func asmIncrementFloat64(ptr *float64) (new float64)
+
+//go:noescape
+func asmDecrementFloat64(ptr *float64) (new float64)
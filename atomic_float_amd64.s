@@ -2,122 +2,446 @@
 
 // See src/runtime/internal/atomic/atomic_amd64.s
 
-// Works but slow
-// float32 AddFloat32(ptr *float32, delta float32)
+// float32 asmAddFloat32(ptr *float32, delta float32)
 // Atomically:
 //	*ptr += delta;
 //	return *ptr;
-TEXT ·AddFloat32(SB), NOSPLIT, $0-20
+TEXT ·asmAddFloat32(SB), NOSPLIT, $0-20
 	MOVQ	ptr+0(FP), BX
-    MOVL    delta+8(FP), X0
-
-    loop:
-    MOVL    0(BX), AX
-    MOVL    AX, X1
-    ADDSS   X0, X1
-    MOVL    X1, CX
-    LOCK
-    CMPXCHGL    CX, 0(BX)
-    SETEQ   AX
-    CMPB    AX, $1
-    JNE loop
-    MOVL    CX, ret+16(FP)
-    RET
+	MOVL	delta+8(FP), X0
+loop:
+	MOVL	0(BX), AX
+	MOVL	AX, X1
+	ADDSS	X0, X1
+	MOVL	X1, CX
+	LOCK
+	CMPXCHGL	CX, 0(BX)
+	JNE	loop
+	MOVL	CX, ret+16(FP)
+	RET
 
-// float32 StoreFloat32(ptr *float32, new float32)
+// asmStoreFloat32(ptr *float32, val float32)
 // Atomically:
-//	*ptr = new;
-TEXT ·StoreFloat32(SB), NOSPLIT, $0-20
-  	MOVQ	ptr+0(FP), BX
-    MOVL    delta+8(FP), AX
-    XCHGL   AX, 0(BX)
-    RET
+//	*ptr = val;
+TEXT ·asmStoreFloat32(SB), NOSPLIT, $0-12
+	MOVQ	ptr+0(FP), BX
+	MOVL	val+8(FP), AX
+	XCHGL	AX, 0(BX)
+	RET
 
-// float32 SwapFloat32(ptr *float32, new float32)
+// float32 asmSwapFloat32(ptr *float32, new float32)
 // Atomically:
 //	old := *ptr;
 //	*ptr = new;
 //	return old;
-TEXT ·SwapFloat32(SB), NOSPLIT, $0-20
+TEXT ·asmSwapFloat32(SB), NOSPLIT, $0-20
 	MOVQ	ptr+0(FP), BX
-    MOVL    delta+8(FP), AX
-    XCHGL   AX, 0(BX)
-    MOVQ	AX, ret+16(FP)
-    RET
+	MOVL	new+8(FP), AX
+	XCHGL	AX, 0(BX)
+	MOVL	AX, ret+16(FP)
+	RET
 
-// bool CompareAndSwapFloat32(float32 *val, float32 old, float32 new)
+// bool asmCompareAndSwapFloat32(ptr *float32, old float32, new float32)
+// Compares bit patterns, not float values.
 // Atomically:
-//	if(*val == old){
-//		*val = new;
+//	if(*ptr == old){
+//		*ptr = new;
 //		return 1;
 //	} else
 //		return 0;
-TEXT ·CompareAndSwapFloat32(SB),NOSPLIT,$0-17
+TEXT ·asmCompareAndSwapFloat32(SB), NOSPLIT, $0-17
 	MOVQ	ptr+0(FP), BX
 	MOVL	old+8(FP), AX
 	MOVL	new+12(FP), CX
 	LOCK
 	CMPXCHGL	CX, 0(BX)
-	SETEQ   AX
-    MOVL    AX, ret+16(FP)
+	SETEQ	ret+16(FP)
+	RET
+
+// asmStoreReleaseFloat32(ptr *float32, val float32)
+// Stores on amd64 already have release semantics, no fence is needed.
+// Atomically:
+//	*ptr = val;
+TEXT ·asmStoreReleaseFloat32(SB), NOSPLIT, $0-12
+	MOVQ	ptr+0(FP), BX
+	MOVL	val+8(FP), AX
+	MOVL	AX, 0(BX)
+	RET
+
+// asmStoreRelaxedFloat32(ptr *float32, val float32)
+TEXT ·asmStoreRelaxedFloat32(SB), NOSPLIT, $0-12
+	JMP	·asmStoreReleaseFloat32(SB)
+
+// float32 asmFMAFloat32(ptr *float32, a, b float32)
+// Requires FMA3, see x86HasFMA.
+// Atomically:
+//	*ptr = a * b + *ptr;
+//	return *ptr;
+TEXT ·asmFMAFloat32(SB), NOSPLIT, $0-20
+	MOVQ	ptr+0(FP), BX
+	MOVSS	a+8(FP), X1
+	MOVSS	b+12(FP), X2
+loop:
+	MOVL	0(BX), AX
+	MOVL	AX, X0
+	VFMADD231SS	X2, X1, X0
+	MOVL	X0, CX
+	LOCK
+	CMPXCHGL	CX, 0(BX)
+	JNE	loop
+	MOVL	CX, ret+16(FP)
+	RET
+
+// float32 asmNegateFloat32(ptr *float32)
+// Adding the sign bit flips it just like XOR does, and XADD also returns the
+// previous value.
+// Atomically:
+//	old := *ptr;
+//	*ptr = -old;
+//	return old;
+TEXT ·asmNegateFloat32(SB), NOSPLIT, $0-12
+	MOVQ	ptr+0(FP), BX
+	MOVL	$0x80000000, AX
+	LOCK
+	XADDL	AX, 0(BX)
+	MOVL	AX, old+8(FP)
+	RET
+
+// float32 asmAndFloat32(ptr *float32, mask uint32)
+// LOCK ANDL does not return the previous value, so loop on CMPXCHGL.
+// Atomically:
+//	old := *ptr;
+//	*ptr = old & mask;
+//	return old;
+TEXT ·asmAndFloat32(SB), NOSPLIT, $0-20
+	MOVQ	ptr+0(FP), BX
+	MOVL	mask+8(FP), DX
+loop:
+	MOVL	0(BX), AX
+	MOVL	AX, CX
+	ANDL	DX, CX
+	LOCK
+	CMPXCHGL	CX, 0(BX)
+	JNE	loop
+	MOVL	AX, old+16(FP)
 	RET
 
-// Works but slow
-// float64 AddFloat64(ptr *float64, delta float64)
+// float32 asmOrFloat32(ptr *float32, mask uint32)
+// Atomically:
+//	old := *ptr;
+//	*ptr = old | mask;
+//	return old;
+TEXT ·asmOrFloat32(SB), NOSPLIT, $0-20
+	MOVQ	ptr+0(FP), BX
+	MOVL	mask+8(FP), DX
+loop:
+	MOVL	0(BX), AX
+	MOVL	AX, CX
+	ORL	DX, CX
+	LOCK
+	CMPXCHGL	CX, 0(BX)
+	JNE	loop
+	MOVL	AX, old+16(FP)
+	RET
+
+// float32 asmIncrementFloat32(ptr *float32)
+// Adjacent floats of the same sign have adjacent bit patterns, so stepping
+// away from zero is INC of the bits and stepping towards zero is DEC.
+// Atomically:
+//	if(*ptr is not NaN or +Inf)
+//		*ptr = nextafter(*ptr, +Inf);
+//	return *ptr;
+TEXT ·asmIncrementFloat32(SB), NOSPLIT, $0-12
+	MOVQ	ptr+0(FP), BX
+	MOVL	$0x7f800000, R8	// +Inf
+	MOVL	$0x80000000, R9	// -0
+loop:
+	MOVL	0(BX), AX
+	MOVL	AX, CX
+	ANDL	$0x7fffffff, CX
+	CMPL	CX, R8
+	JHI	done	// NaN
+	CMPL	AX, R8
+	JEQ	done
+	MOVL	AX, CX
+	CMPL	AX, R9
+	JEQ	zero
+	TESTL	AX, AX
+	JS	neg
+	INCL	CX
+	JMP	cas
+neg:
+	DECL	CX
+	JMP	cas
+zero:
+	MOVL	$1, CX
+cas:
+	LOCK
+	CMPXCHGL	CX, 0(BX)
+	JNE	loop
+	MOVL	CX, new+8(FP)
+	RET
+done:
+	MOVL	AX, new+8(FP)
+	RET
+
+// float32 asmDecrementFloat32(ptr *float32)
+// Atomically:
+//	if(*ptr is not NaN or -Inf)
+//		*ptr = nextafter(*ptr, -Inf);
+//	return *ptr;
+TEXT ·asmDecrementFloat32(SB), NOSPLIT, $0-12
+	MOVQ	ptr+0(FP), BX
+	MOVL	$0x7f800000, R8	// +Inf
+	MOVL	$0xff800000, R9	// -Inf
+loop:
+	MOVL	0(BX), AX
+	MOVL	AX, CX
+	ANDL	$0x7fffffff, CX
+	CMPL	CX, R8
+	JHI	done	// NaN
+	CMPL	AX, R9
+	JEQ	done
+	MOVL	AX, CX
+	TESTL	AX, AX
+	JZ	zero
+	JS	neg
+	DECL	CX
+	JMP	cas
+neg:
+	INCL	CX
+	JMP	cas
+zero:
+	MOVL	$0x80000001, CX
+cas:
+	LOCK
+	CMPXCHGL	CX, 0(BX)
+	JNE	loop
+	MOVL	CX, new+8(FP)
+	RET
+done:
+	MOVL	AX, new+8(FP)
+	RET
+
+// float64 asmAddFloat64(ptr *float64, delta float64)
 // Atomically:
 //	*ptr += delta;
 //	return *ptr;
-TEXT ·AddFloat64(SB), NOSPLIT, $0-24
-    MOVQ	ptr+0(FP), BX
-    MOVQ    delta+8(FP), X0
-
-    loop:
-    MOVQ    0(BX), AX
-    MOVQ    AX, X1
-    ADDSD   X0, X1
-    MOVQ    X1, CX
-    LOCK
-    CMPXCHGQ    CX, 0(BX)
-    SETEQ   AX
-    CMPB    AX, $1
-    JNE loop
-    MOVQ    CX, ret+16(FP)
-    RET
-
-// float64 StoreFloat64(ptr *float64, new float64)
+TEXT ·asmAddFloat64(SB), NOSPLIT, $0-24
+	MOVQ	ptr+0(FP), BX
+	MOVQ	delta+8(FP), X0
+loop:
+	MOVQ	0(BX), AX
+	MOVQ	AX, X1
+	ADDSD	X0, X1
+	MOVQ	X1, CX
+	LOCK
+	CMPXCHGQ	CX, 0(BX)
+	JNE	loop
+	MOVQ	CX, ret+16(FP)
+	RET
+
+// asmStoreFloat64(ptr *float64, val float64)
 // Atomically:
-//	*ptr = new;
-TEXT ·StoreFloat64(SB), NOSPLIT, $0-24
-    MOVQ	ptr+0(FP), BX
-    MOVQ    delta+8(FP), AX
-    XCHGQ   AX, 0(BX)
-    RET
+//	*ptr = val;
+TEXT ·asmStoreFloat64(SB), NOSPLIT, $0-16
+	MOVQ	ptr+0(FP), BX
+	MOVQ	val+8(FP), AX
+	XCHGQ	AX, 0(BX)
+	RET
 
-// float64 SwapFloat64(ptr *float64, new float64)
+// float64 asmSwapFloat64(ptr *float64, new float64)
 // Atomically:
 //	old := *ptr;
 //	*ptr = new;
 //	return old;
-TEXT ·SwapFloat64(SB), NOSPLIT, $0-24
-    MOVQ	ptr+0(FP), BX
-    MOVQ    delta+8(FP), AX
-    XCHGQ   AX, 0(BX)
-    MOVQ	AX, ret+16(FP)
-    RET
+TEXT ·asmSwapFloat64(SB), NOSPLIT, $0-24
+	MOVQ	ptr+0(FP), BX
+	MOVQ	new+8(FP), AX
+	XCHGQ	AX, 0(BX)
+	MOVQ	AX, ret+16(FP)
+	RET
 
-// bool CompareAndSwapFloat64(float64 *val, float64 old, float64 new)
+// bool asmCompareAndSwapFloat64(ptr *float64, old float64, new float64)
+// Compares bit patterns, not float values.
 // Atomically:
-//	if(*val == old){
-//		*val = new;
+//	if(*ptr == old){
+//		*ptr = new;
 //		return 1;
 //	} else
 //		return 0;
-TEXT ·CompareAndSwapFloat64(SB),NOSPLIT,$0-25
-    MOVQ	ptr+0(FP), BX
+TEXT ·asmCompareAndSwapFloat64(SB), NOSPLIT, $0-25
+	MOVQ	ptr+0(FP), BX
 	MOVQ	old+8(FP), AX
-	MOVQ    new+16(FP), CX
+	MOVQ	new+16(FP), CX
+	LOCK
+	CMPXCHGQ	CX, 0(BX)
+	SETEQ	ret+24(FP)
+	RET
+
+// asmStoreReleaseFloat64(ptr *float64, val float64)
+// Stores on amd64 already have release semantics, no fence is needed.
+// Atomically:
+//	*ptr = val;
+TEXT ·asmStoreReleaseFloat64(SB), NOSPLIT, $0-16
+	MOVQ	ptr+0(FP), BX
+	MOVQ	val+8(FP), AX
+	MOVQ	AX, 0(BX)
+	RET
+
+// asmStoreRelaxedFloat64(ptr *float64, val float64)
+TEXT ·asmStoreRelaxedFloat64(SB), NOSPLIT, $0-16
+	JMP	·asmStoreReleaseFloat64(SB)
+
+// float64 asmFMAFloat64(ptr *float64, a, b float64)
+// Requires FMA3, see x86HasFMA.
+// Atomically:
+//	*ptr = a * b + *ptr;
+//	return *ptr;
+TEXT ·asmFMAFloat64(SB), NOSPLIT, $0-32
+	MOVQ	ptr+0(FP), BX
+	MOVSD	a+8(FP), X1
+	MOVSD	b+16(FP), X2
+loop:
+	MOVQ	0(BX), AX
+	MOVQ	AX, X0
+	VFMADD231SD	X2, X1, X0
+	MOVQ	X0, CX
+	LOCK
+	CMPXCHGQ	CX, 0(BX)
+	JNE	loop
+	MOVQ	CX, ret+24(FP)
+	RET
+
+// float64 asmNegateFloat64(ptr *float64)
+// Adding the sign bit flips it just like XOR does, and XADD also returns the
+// previous value.
+// Atomically:
+//	old := *ptr;
+//	*ptr = -old;
+//	return old;
+TEXT ·asmNegateFloat64(SB), NOSPLIT, $0-16
+	MOVQ	ptr+0(FP), BX
+	MOVQ	$0x8000000000000000, AX
 	LOCK
-	CMPXCHGQ    CX, 0(BX)
-	SETEQ   AX
-    MOVQ    AX, ret+24(FP)
+	XADDQ	AX, 0(BX)
+	MOVQ	AX, old+8(FP)
+	RET
+
+// float64 asmAndFloat64(ptr *float64, mask uint64)
+// LOCK ANDQ does not return the previous value, so loop on CMPXCHGQ.
+// Atomically:
+//	old := *ptr;
+//	*ptr = old & mask;
+//	return old;
+TEXT ·asmAndFloat64(SB), NOSPLIT, $0-24
+	MOVQ	ptr+0(FP), BX
+	MOVQ	mask+8(FP), DX
+loop:
+	MOVQ	0(BX), AX
+	MOVQ	AX, CX
+	ANDQ	DX, CX
+	LOCK
+	CMPXCHGQ	CX, 0(BX)
+	JNE	loop
+	MOVQ	AX, old+16(FP)
+	RET
+
+// float64 asmOrFloat64(ptr *float64, mask uint64)
+// Atomically:
+//	old := *ptr;
+//	*ptr = old | mask;
+//	return old;
+TEXT ·asmOrFloat64(SB), NOSPLIT, $0-24
+	MOVQ	ptr+0(FP), BX
+	MOVQ	mask+8(FP), DX
+loop:
+	MOVQ	0(BX), AX
+	MOVQ	AX, CX
+	ORQ	DX, CX
+	LOCK
+	CMPXCHGQ	CX, 0(BX)
+	JNE	loop
+	MOVQ	AX, old+16(FP)
+	RET
+
+// float64 asmIncrementFloat64(ptr *float64)
+// Adjacent floats of the same sign have adjacent bit patterns, so stepping
+// away from zero is INC of the bits and stepping towards zero is DEC.
+// Atomically:
+//	if(*ptr is not NaN or +Inf)
+//		*ptr = nextafter(*ptr, +Inf);
+//	return *ptr;
+TEXT ·asmIncrementFloat64(SB), NOSPLIT, $0-16
+	MOVQ	ptr+0(FP), BX
+	MOVQ	$0x7ff0000000000000, R8	// +Inf
+	MOVQ	$0x8000000000000000, R9	// -0
+	MOVQ	$0x7fffffffffffffff, R10
+loop:
+	MOVQ	0(BX), AX
+	MOVQ	AX, CX
+	ANDQ	R10, CX
+	CMPQ	CX, R8
+	JHI	done	// NaN
+	CMPQ	AX, R8
+	JEQ	done
+	MOVQ	AX, CX
+	CMPQ	AX, R9
+	JEQ	zero
+	TESTQ	AX, AX
+	JS	neg
+	INCQ	CX
+	JMP	cas
+neg:
+	DECQ	CX
+	JMP	cas
+zero:
+	MOVQ	$1, CX
+cas:
+	LOCK
+	CMPXCHGQ	CX, 0(BX)
+	JNE	loop
+	MOVQ	CX, new+8(FP)
+	RET
+done:
+	MOVQ	AX, new+8(FP)
+	RET
+
+// float64 asmDecrementFloat64(ptr *float64)
+// Atomically:
+//	if(*ptr is not NaN or -Inf)
+//		*ptr = nextafter(*ptr, -Inf);
+//	return *ptr;
+TEXT ·asmDecrementFloat64(SB), NOSPLIT, $0-16
+	MOVQ	ptr+0(FP), BX
+	MOVQ	$0x7ff0000000000000, R8	// +Inf
+	MOVQ	$0xfff0000000000000, R9	// -Inf
+	MOVQ	$0x7fffffffffffffff, R10
+loop:
+	MOVQ	0(BX), AX
+	MOVQ	AX, CX
+	ANDQ	R10, CX
+	CMPQ	CX, R8
+	JHI	done	// NaN
+	CMPQ	AX, R9
+	JEQ	done
+	MOVQ	AX, CX
+	TESTQ	AX, AX
+	JZ	zero
+	JS	neg
+	DECQ	CX
+	JMP	cas
+neg:
+	INCQ	CX
+	JMP	cas
+zero:
+	MOVQ	$0x8000000000000001, CX
+cas:
+	LOCK
+	CMPXCHGQ	CX, 0(BX)
+	JNE	loop
+	MOVQ	CX, new+8(FP)
+	RET
+done:
+	MOVQ	AX, new+8(FP)
 	RET
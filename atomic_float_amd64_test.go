@@ -0,0 +1,395 @@
+package atomic_float
+
+import (
+	"math"
+	"testing"
+)
+
+func init() {
+	if x86HasFMA {
+		fmaFloat32Impls["asmFMAFloat32"] = asmFMAFloat32
+		fmaFloat64Impls["asmFMAFloat64"] = asmFMAFloat64
+	}
+}
+
+// TestAsmExtrasMatchDefaultFloat32 checks that the amd64 only assembly and
+// the sync/atomic implementations agree bit for bit.
+func TestAsmExtrasMatchDefaultFloat32(t *testing.T) {
+	type op func(ptr *float32, v float32) (float32, bool)
+	pairs := map[string][2]op{
+		"StoreRelease": {
+			func(p *float32, v float32) (float32, bool) { asmStoreReleaseFloat32(p, v); return 0, false },
+			func(p *float32, v float32) (float32, bool) { StoreReleaseFloat32(p, v); return 0, false },
+		},
+		"Negate": {
+			func(p *float32, v float32) (float32, bool) { return asmNegateFloat32(p), false },
+			func(p *float32, v float32) (float32, bool) { return NegateFloat32(p), false },
+		},
+		"Increment": {
+			func(p *float32, v float32) (float32, bool) { return asmIncrementFloat32(p), false },
+			func(p *float32, v float32) (float32, bool) { return IncrementFloat32(p), false },
+		},
+		"Decrement": {
+			func(p *float32, v float32) (float32, bool) { return asmDecrementFloat32(p), false },
+			func(p *float32, v float32) (float32, bool) { return DecrementFloat32(p), false },
+		},
+		"And": {
+			func(p *float32, v float32) (float32, bool) { return asmAndFloat32(p, math.Float32bits(v)), false },
+			func(p *float32, v float32) (float32, bool) { return andFloat32(p, math.Float32bits(v)), false },
+		},
+		"Or": {
+			func(p *float32, v float32) (float32, bool) { return asmOrFloat32(p, math.Float32bits(v)), false },
+			func(p *float32, v float32) (float32, bool) { return orFloat32(p, math.Float32bits(v)), false },
+		},
+	}
+	for name, pair := range pairs {
+		for _, x := range float32Edges {
+			for _, v := range float32Edges {
+				a, b := x, x
+				ra, oka := pair[0](&a, v)
+				rb, okb := pair[1](&b, v)
+				if math.Float32bits(ra) != math.Float32bits(rb) || oka != okb || math.Float32bits(a) != math.Float32bits(b) {
+					t.Errorf("%s(%v, %v): asm gave %v, %v and left %v; default gave %v, %v and left %v",
+						name, x, v, ra, oka, a, rb, okb, b)
+				}
+			}
+		}
+	}
+}
+
+// TestAsmExtrasMatchDefaultFloat64 checks that the amd64 only assembly and
+// the sync/atomic implementations agree bit for bit.
+func TestAsmExtrasMatchDefaultFloat64(t *testing.T) {
+	type op func(ptr *float64, v float64) (float64, bool)
+	pairs := map[string][2]op{
+		"StoreRelease": {
+			func(p *float64, v float64) (float64, bool) { asmStoreReleaseFloat64(p, v); return 0, false },
+			func(p *float64, v float64) (float64, bool) { StoreReleaseFloat64(p, v); return 0, false },
+		},
+		"Negate": {
+			func(p *float64, v float64) (float64, bool) { return asmNegateFloat64(p), false },
+			func(p *float64, v float64) (float64, bool) { return NegateFloat64(p), false },
+		},
+		"Increment": {
+			func(p *float64, v float64) (float64, bool) { return asmIncrementFloat64(p), false },
+			func(p *float64, v float64) (float64, bool) { return IncrementFloat64(p), false },
+		},
+		"Decrement": {
+			func(p *float64, v float64) (float64, bool) { return asmDecrementFloat64(p), false },
+			func(p *float64, v float64) (float64, bool) { return DecrementFloat64(p), false },
+		},
+		"And": {
+			func(p *float64, v float64) (float64, bool) { return asmAndFloat64(p, math.Float64bits(v)), false },
+			func(p *float64, v float64) (float64, bool) { return andFloat64(p, math.Float64bits(v)), false },
+		},
+		"Or": {
+			func(p *float64, v float64) (float64, bool) { return asmOrFloat64(p, math.Float64bits(v)), false },
+			func(p *float64, v float64) (float64, bool) { return orFloat64(p, math.Float64bits(v)), false },
+		},
+	}
+	for name, pair := range pairs {
+		for _, x := range float64Edges {
+			for _, v := range float64Edges {
+				a, b := x, x
+				ra, oka := pair[0](&a, v)
+				rb, okb := pair[1](&b, v)
+				if math.Float64bits(ra) != math.Float64bits(rb) || oka != okb || math.Float64bits(a) != math.Float64bits(b) {
+					t.Errorf("%s(%v, %v): asm gave %v, %v and left %v; default gave %v, %v and left %v",
+						name, x, v, ra, oka, a, rb, okb, b)
+				}
+			}
+		}
+	}
+}
+
+// Benchmarks of the assembly implementation. Each one mirrors the benchmark
+// of the same name without the _Asm suffix in atomic_float_timing_test.go,
+// which measures whichever implementation the build selected.
+
+func BenchmarkLoadFloat32_Asm(b *testing.B) {
+	var x Float32
+	x.Store(2.5)
+	var sum float32
+	for i := 0; i < b.N; i++ {
+		sum += asmLoadFloat32(&x.v)
+	}
+	_ = sum
+}
+
+func BenchmarkLoadFloat32Parallel_Asm(b *testing.B) {
+	var x Float32
+	x.Store(2.5)
+	b.RunParallel(func(pb *testing.PB) {
+		var sum float32
+		for pb.Next() {
+			sum += asmLoadFloat32(&x.v)
+		}
+		_ = sum
+	})
+}
+
+func BenchmarkLoadFloat64_Asm(b *testing.B) {
+	var x Float64
+	x.Store(2.5)
+	var sum float64
+	for i := 0; i < b.N; i++ {
+		sum += asmLoadFloat64(&x.v)
+	}
+	_ = sum
+}
+
+func BenchmarkLoadFloat64Parallel_Asm(b *testing.B) {
+	var x Float64
+	x.Store(2.5)
+	b.RunParallel(func(pb *testing.PB) {
+		var sum float64
+		for pb.Next() {
+			sum += asmLoadFloat64(&x.v)
+		}
+		_ = sum
+	})
+}
+
+func BenchmarkAddFloat32_Asm(b *testing.B) {
+	var x Float32
+	var y float32 = 2.5
+	for i := 0; i < b.N; i++ {
+		asmAddFloat32(&x.v, y)
+	}
+}
+
+func BenchmarkAddFloat32Parallel_Asm(b *testing.B) {
+	var x Float32
+	var delta float32 = 2.5
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			asmAddFloat32(&x.v, delta)
+		}
+	})
+}
+
+func BenchmarkStoreFloat32_Asm(b *testing.B) {
+	var x Float32
+	for i := 0; i < b.N; i++ {
+		asmStoreFloat32(&x.v, float32(i))
+		if res := asmLoadFloat32(&x.v); res != float32(i) {
+			b.Errorf("Expected %v, got %v", float32(i), res)
+		}
+	}
+}
+
+func BenchmarkStoreFloat32Parallel_Asm(b *testing.B) {
+	var x Float32
+	var delta float32 = 1.1
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			asmStoreFloat32(&x.v, delta)
+		}
+	})
+}
+
+func BenchmarkStoreReleaseFloat32_Asm(b *testing.B) {
+	var x Float32
+	for i := 0; i < b.N; i++ {
+		asmStoreReleaseFloat32(&x.v, float32(i))
+		if res := asmLoadFloat32(&x.v); res != float32(i) {
+			b.Errorf("Expected %v, got %v", float32(i), res)
+		}
+	}
+}
+
+func BenchmarkStoreReleaseFloat32Parallel_Asm(b *testing.B) {
+	var x Float32
+	var delta float32 = 1.1
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			asmStoreReleaseFloat32(&x.v, delta)
+		}
+	})
+}
+
+func BenchmarkStoreRelaxedFloat32_Asm(b *testing.B) {
+	var x Float32
+	for i := 0; i < b.N; i++ {
+		asmStoreRelaxedFloat32(&x.v, float32(i))
+		if res := asmLoadFloat32(&x.v); res != float32(i) {
+			b.Errorf("Expected %v, got %v", float32(i), res)
+		}
+	}
+}
+
+func BenchmarkStoreRelaxedFloat32Parallel_Asm(b *testing.B) {
+	var x Float32
+	var delta float32 = 1.1
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			asmStoreRelaxedFloat32(&x.v, delta)
+		}
+	})
+}
+
+func BenchmarkSwapFloat32_Asm(b *testing.B) {
+	var x Float32
+	for i := 1; i < b.N; i++ {
+		if result := asmSwapFloat32(&x.v, float32(i)); result != float32(i-1) {
+			b.Errorf("Expected %v, got %v", float32(i-1), result)
+		}
+		if res := asmLoadFloat32(&x.v); res != float32(i) {
+			b.Errorf("Expected %v, got %v", float32(i), res)
+		}
+	}
+}
+
+func BenchmarkSwapFloat32Parallel_Asm(b *testing.B) {
+	var x Float32
+	var delta float32 = 1.1
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			asmSwapFloat32(&x.v, delta)
+		}
+	})
+}
+
+func BenchmarkCASFloat32_Asm(b *testing.B) {
+	var x Float32
+	for i := 1; i < b.N; i++ {
+		if result := asmCompareAndSwapFloat32(&x.v, float32(i-1), float32(i)); result != true {
+			b.Errorf("Expected %v, got %v", true, result)
+		}
+		if res := asmLoadFloat32(&x.v); res != float32(i) {
+			b.Errorf("Expected %v, got %v", float32(i), res)
+		}
+	}
+}
+
+func BenchmarkCASFloat32Parallel_Asm(b *testing.B) {
+	var x Float32
+	var delta float32 = 1.1
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			asmCompareAndSwapFloat32(&x.v, 0.0, delta)
+		}
+	})
+}
+
+func BenchmarkAddFloat64_Asm(b *testing.B) {
+	var x Float64
+	var y float64 = 2.5
+	for i := 0; i < b.N; i++ {
+		asmAddFloat64(&x.v, y)
+	}
+}
+
+func BenchmarkAddFloat64Parallel_Asm(b *testing.B) {
+	var x Float64
+	var delta float64 = 2.5
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			asmAddFloat64(&x.v, delta)
+		}
+	})
+}
+
+func BenchmarkStoreFloat64_Asm(b *testing.B) {
+	var x Float64
+	for i := 0; i < b.N; i++ {
+		asmStoreFloat64(&x.v, float64(i))
+		if res := asmLoadFloat64(&x.v); res != float64(i) {
+			b.Errorf("Expected %v, got %v", float64(i), res)
+		}
+	}
+}
+
+func BenchmarkStoreFloat64Parallel_Asm(b *testing.B) {
+	var x Float64
+	var delta float64 = 1.1
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			asmStoreFloat64(&x.v, delta)
+		}
+	})
+}
+
+func BenchmarkStoreReleaseFloat64_Asm(b *testing.B) {
+	var x Float64
+	for i := 0; i < b.N; i++ {
+		asmStoreReleaseFloat64(&x.v, float64(i))
+		if res := asmLoadFloat64(&x.v); res != float64(i) {
+			b.Errorf("Expected %v, got %v", float64(i), res)
+		}
+	}
+}
+
+func BenchmarkStoreReleaseFloat64Parallel_Asm(b *testing.B) {
+	var x Float64
+	var delta float64 = 1.1
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			asmStoreReleaseFloat64(&x.v, delta)
+		}
+	})
+}
+
+func BenchmarkStoreRelaxedFloat64_Asm(b *testing.B) {
+	var x Float64
+	for i := 0; i < b.N; i++ {
+		asmStoreRelaxedFloat64(&x.v, float64(i))
+		if res := asmLoadFloat64(&x.v); res != float64(i) {
+			b.Errorf("Expected %v, got %v", float64(i), res)
+		}
+	}
+}
+
+func BenchmarkStoreRelaxedFloat64Parallel_Asm(b *testing.B) {
+	var x Float64
+	var delta float64 = 1.1
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			asmStoreRelaxedFloat64(&x.v, delta)
+		}
+	})
+}
+
+func BenchmarkSwapFloat64_Asm(b *testing.B) {
+	var x Float64
+	for i := 1; i < b.N; i++ {
+		if result := asmSwapFloat64(&x.v, float64(i)); result != float64(i-1) {
+			b.Errorf("Expected %v, got %v", float64(i-1), result)
+		}
+		if res := asmLoadFloat64(&x.v); res != float64(i) {
+			b.Errorf("Expected %v, got %v", float64(i), res)
+		}
+	}
+}
+
+func BenchmarkSwapFloat64Parallel_Asm(b *testing.B) {
+	var x Float64
+	var delta float64 = 1.1
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			asmSwapFloat64(&x.v, delta)
+		}
+	})
+}
+
+func BenchmarkCASFloat64_Asm(b *testing.B) {
+	var x Float64
+	for i := 1; i < b.N; i++ {
+		if result := asmCompareAndSwapFloat64(&x.v, float64(i-1), float64(i)); result != true {
+			b.Errorf("Expected %v, got %v", true, result)
+		}
+		if res := asmLoadFloat64(&x.v); res != float64(i) {
+			b.Errorf("Expected %v, got %v", float64(i), res)
+		}
+	}
+}
+
+func BenchmarkCASFloat64Parallel_Asm(b *testing.B) {
+	var x Float64
+	var delta float64 = 1.1
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			asmCompareAndSwapFloat64(&x.v, 0.0, delta)
+		}
+	})
+}
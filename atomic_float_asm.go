@@ -0,0 +1,57 @@
+//go:build (amd64 || 386) && atomicfloat_asm
+
+package atomic_float
+
+// Assembly implementation selected by the atomicfloat_asm build tag. Each
+// function is an out-of-line call into atomic_float_amd64.s or
+// atomic_float_386.s; the default implementation in atomic_float_intrinsic.go
+// inlines instead.
+
+// LoadFloat32 atomically loads *ptr.
+func LoadFloat32(ptr *float32) float32 { return asmLoadFloat32(ptr) }
+
+// AddFloat32 atomically adds delta to *ptr and returns the new value.
+func AddFloat32(ptr *float32, delta float32) (new float32) { return asmAddFloat32(ptr, delta) }
+
+// StoreFloat32 atomically stores val into *ptr.
+func StoreFloat32(ptr *float32, val float32) { asmStoreFloat32(ptr, val) }
+
+// SwapFloat32 atomically stores new into *ptr and returns the previous value.
+func SwapFloat32(ptr *float32, new float32) (old float32) { return asmSwapFloat32(ptr, new) }
+
+// CompareAndSwapFloat32 executes the compare-and-swap operation for a float32
+// value. The values are compared by their bit patterns.
+func CompareAndSwapFloat32(ptr *float32, old, new float32) (swapped bool) {
+	return asmCompareAndSwapFloat32(ptr, old, new)
+}
+
+// LoadFloat64 atomically loads *ptr.
+func LoadFloat64(ptr *float64) float64 {
+	checkAligned64(ptr)
+	return asmLoadFloat64(ptr)
+}
+
+// AddFloat64 atomically adds delta to *ptr and returns the new value.
+func AddFloat64(ptr *float64, delta float64) (new float64) {
+	checkAligned64(ptr)
+	return asmAddFloat64(ptr, delta)
+}
+
+// StoreFloat64 atomically stores val into *ptr.
+func StoreFloat64(ptr *float64, val float64) {
+	checkAligned64(ptr)
+	asmStoreFloat64(ptr, val)
+}
+
+// SwapFloat64 atomically stores new into *ptr and returns the previous value.
+func SwapFloat64(ptr *float64, new float64) (old float64) {
+	checkAligned64(ptr)
+	return asmSwapFloat64(ptr, new)
+}
+
+// CompareAndSwapFloat64 executes the compare-and-swap operation for a float64
+// value. The values are compared by their bit patterns.
+func CompareAndSwapFloat64(ptr *float64, old, new float64) (swapped bool) {
+	checkAligned64(ptr)
+	return asmCompareAndSwapFloat64(ptr, old, new)
+}
@@ -0,0 +1,75 @@
+//go:build atomicfloat_asm
+
+package atomic_float
+
+// Operations that only have an assembly implementation on amd64.
+
+// FMAFloat32 atomically computes *ptr = a*b + *ptr with a single rounding and
+// returns the new value. It uses VFMADD231SS on CPUs with FMA3.
+func FMAFloat32(ptr *float32, a, b float32) float32 {
+	if x86HasFMA {
+		return asmFMAFloat32(ptr, a, b)
+	}
+	return casFMAFloat32(ptr, a, b)
+}
+
+// NegateFloat32 atomically flips the sign of *ptr and returns the previous
+// value.
+func NegateFloat32(ptr *float32) (old float32) { return asmNegateFloat32(ptr) }
+
+func andFloat32(ptr *float32, mask uint32) (old float32) { return asmAndFloat32(ptr, mask) }
+
+func orFloat32(ptr *float32, mask uint32) (old float32) { return asmOrFloat32(ptr, mask) }
+
+// IncrementFloat32 atomically replaces *ptr with the next representable
+// float32 towards +Inf and returns the new value. +Inf and NaN are left
+// unchanged, and -0 steps to the smallest positive subnormal like +0 does.
+func IncrementFloat32(ptr *float32) (new float32) { return asmIncrementFloat32(ptr) }
+
+// DecrementFloat32 atomically replaces *ptr with the next representable
+// float32 towards -Inf and returns the new value. -Inf and NaN are left
+// unchanged, and +0 steps to the smallest negative subnormal like -0 does.
+func DecrementFloat32(ptr *float32) (new float32) { return asmDecrementFloat32(ptr) }
+
+// FMAFloat64 atomically computes *ptr = a*b + *ptr with a single rounding, as
+// math.FMA does, and returns the new value.
+func FMAFloat64(ptr *float64, a, b float64) float64 {
+	checkAligned64(ptr)
+	if x86HasFMA {
+		return asmFMAFloat64(ptr, a, b)
+	}
+	return casFMAFloat64(ptr, a, b)
+}
+
+// NegateFloat64 atomically flips the sign of *ptr and returns the previous
+// value.
+func NegateFloat64(ptr *float64) (old float64) {
+	checkAligned64(ptr)
+	return asmNegateFloat64(ptr)
+}
+
+func andFloat64(ptr *float64, mask uint64) (old float64) {
+	checkAligned64(ptr)
+	return asmAndFloat64(ptr, mask)
+}
+
+func orFloat64(ptr *float64, mask uint64) (old float64) {
+	checkAligned64(ptr)
+	return asmOrFloat64(ptr, mask)
+}
+
+// IncrementFloat64 atomically replaces *ptr with the next representable
+// float64 towards +Inf and returns the new value. +Inf and NaN are left
+// unchanged, and -0 steps to the smallest positive subnormal like +0 does.
+func IncrementFloat64(ptr *float64) (new float64) {
+	checkAligned64(ptr)
+	return asmIncrementFloat64(ptr)
+}
+
+// DecrementFloat64 atomically replaces *ptr with the next representable
+// float64 towards -Inf and returns the new value. -Inf and NaN are left
+// unchanged, and +0 steps to the smallest negative subnormal like -0 does.
+func DecrementFloat64(ptr *float64) (new float64) {
+	checkAligned64(ptr)
+	return asmDecrementFloat64(ptr)
+}
@@ -0,0 +1,162 @@
+//go:build !amd64 || !atomicfloat_asm
+
+package atomic_float
+
+import (
+	"math"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Operations without an assembly implementation for the target, built on
+// sync/atomic.
+
+// FMAFloat32 atomically computes *ptr = a*b + *ptr with a single rounding and
+// returns the new value.
+func FMAFloat32(ptr *float32, a, b float32) float32 {
+	return casFMAFloat32(ptr, a, b)
+}
+
+// FMAFloat64 atomically computes *ptr = a*b + *ptr with a single rounding, as
+// math.FMA does, and returns the new value.
+func FMAFloat64(ptr *float64, a, b float64) float64 {
+	return casFMAFloat64(ptr, a, b)
+}
+
+// Sign bit operations. XOR with the sign bit is an addition of the sign bit,
+// so Negate is a single atomic add. sync/atomic's And and Or need go1.23, so
+// they are CompareAndSwap loops here.
+
+// NegateFloat32 atomically flips the sign of *ptr and returns the previous
+// value.
+func NegateFloat32(ptr *float32) (old float32) {
+	return math.Float32frombits(atomic.AddUint32((*uint32)(unsafe.Pointer(ptr)), 1<<31) ^ 1<<31)
+}
+
+func andFloat32(ptr *float32, mask uint32) (old float32) {
+	p := (*uint32)(unsafe.Pointer(ptr))
+	for {
+		o := atomic.LoadUint32(p)
+		if atomic.CompareAndSwapUint32(p, o, o&mask) {
+			return math.Float32frombits(o)
+		}
+	}
+}
+
+func orFloat32(ptr *float32, mask uint32) (old float32) {
+	p := (*uint32)(unsafe.Pointer(ptr))
+	for {
+		o := atomic.LoadUint32(p)
+		if atomic.CompareAndSwapUint32(p, o, o|mask) {
+			return math.Float32frombits(o)
+		}
+	}
+}
+
+// NegateFloat64 atomically flips the sign of *ptr and returns the previous
+// value.
+func NegateFloat64(ptr *float64) (old float64) {
+	checkAligned64(ptr)
+	return math.Float64frombits(atomic.AddUint64((*uint64)(unsafe.Pointer(ptr)), 1<<63) ^ 1<<63)
+}
+
+func andFloat64(ptr *float64, mask uint64) (old float64) {
+	checkAligned64(ptr)
+	p := (*uint64)(unsafe.Pointer(ptr))
+	for {
+		o := atomic.LoadUint64(p)
+		if atomic.CompareAndSwapUint64(p, o, o&mask) {
+			return math.Float64frombits(o)
+		}
+	}
+}
+
+func orFloat64(ptr *float64, mask uint64) (old float64) {
+	checkAligned64(ptr)
+	p := (*uint64)(unsafe.Pointer(ptr))
+	for {
+		o := atomic.LoadUint64(p)
+		if atomic.CompareAndSwapUint64(p, o, o|mask) {
+			return math.Float64frombits(o)
+		}
+	}
+}
+
+// ULP steps. Adjacent floats of the same sign have adjacent bit patterns, so
+// stepping away from zero adds one to the bits and stepping towards zero
+// subtracts one.
+
+// IncrementFloat32 atomically replaces *ptr with the next representable
+// float32 towards +Inf and returns the new value. +Inf and NaN are left
+// unchanged, and -0 steps to the smallest positive subnormal like +0 does.
+func IncrementFloat32(ptr *float32) (new float32) {
+	return stepFloat32(ptr, 0)
+}
+
+// DecrementFloat32 atomically replaces *ptr with the next representable
+// float32 towards -Inf and returns the new value. -Inf and NaN are left
+// unchanged, and +0 steps to the smallest negative subnormal like -0 does.
+func DecrementFloat32(ptr *float32) (new float32) {
+	return stepFloat32(ptr, 1<<31)
+}
+
+// stepFloat32 moves *ptr one ULP towards the infinity with sign bit dir.
+func stepFloat32(ptr *float32, dir uint32) float32 {
+	const inf = 0x7f80_0000
+	p := (*uint32)(unsafe.Pointer(ptr))
+	for {
+		o := atomic.LoadUint32(p)
+		var n uint32
+		switch {
+		case o&^(1<<31) > inf, o == inf|dir:
+			return math.Float32frombits(o)
+		case o == 1<<31^dir:
+			n = dir | 1
+		case o&(1<<31) == dir:
+			n = o + 1
+		default:
+			n = o - 1
+		}
+		if atomic.CompareAndSwapUint32(p, o, n) {
+			return math.Float32frombits(n)
+		}
+	}
+}
+
+// IncrementFloat64 atomically replaces *ptr with the next representable
+// float64 towards +Inf and returns the new value. +Inf and NaN are left
+// unchanged, and -0 steps to the smallest positive subnormal like +0 does.
+func IncrementFloat64(ptr *float64) (new float64) {
+	return stepFloat64(ptr, 0)
+}
+
+// DecrementFloat64 atomically replaces *ptr with the next representable
+// float64 towards -Inf and returns the new value. -Inf and NaN are left
+// unchanged, and +0 steps to the smallest negative subnormal like -0 does.
+func DecrementFloat64(ptr *float64) (new float64) {
+	return stepFloat64(ptr, 1<<63)
+}
+
+// stepFloat64 moves *ptr one ULP towards the infinity with sign bit dir.
+func stepFloat64(ptr *float64, dir uint64) float64 {
+	checkAligned64(ptr)
+	const inf = 0x7ff0_0000_0000_0000
+	p := (*uint64)(unsafe.Pointer(ptr))
+	for {
+		o := atomic.LoadUint64(p)
+		var n uint64
+		switch {
+		case o&^(1<<63) > inf, o == inf|dir:
+			return math.Float64frombits(o)
+		case o == 1<<63^dir:
+			n = dir | 1
+		case o&(1<<63) == dir:
+			n = o + 1
+		default:
+			n = o - 1
+		}
+		if atomic.CompareAndSwapUint64(p, o, n) {
+			return math.Float64frombits(n)
+		}
+	}
+}
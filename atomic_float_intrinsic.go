@@ -0,0 +1,86 @@
+//go:build !(amd64 || 386) || !atomicfloat_asm
+
+package atomic_float
+
+import (
+	"math"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Default implementation on top of sync/atomic. The sync/atomic functions
+// are compiler intrinsics, so each operation here inlines to the same locked
+// instruction the assembly in atomic_float_amd64.s would execute, without the
+// call. Build with the atomicfloat_asm tag to use the assembly on amd64 and
+// 386.
+
+// LoadFloat32 atomically loads *ptr.
+func LoadFloat32(ptr *float32) float32 {
+	return math.Float32frombits(atomic.LoadUint32((*uint32)(unsafe.Pointer(ptr))))
+}
+
+// AddFloat32 atomically adds delta to *ptr and returns the new value.
+func AddFloat32(ptr *float32, delta float32) (new float32) {
+	p := (*uint32)(unsafe.Pointer(ptr))
+	for {
+		o := atomic.LoadUint32(p)
+		n := math.Float32bits(math.Float32frombits(o) + delta)
+		if atomic.CompareAndSwapUint32(p, o, n) {
+			return math.Float32frombits(n)
+		}
+	}
+}
+
+// StoreFloat32 atomically stores val into *ptr.
+func StoreFloat32(ptr *float32, val float32) {
+	atomic.StoreUint32((*uint32)(unsafe.Pointer(ptr)), math.Float32bits(val))
+}
+
+// SwapFloat32 atomically stores new into *ptr and returns the previous value.
+func SwapFloat32(ptr *float32, new float32) (old float32) {
+	return math.Float32frombits(atomic.SwapUint32((*uint32)(unsafe.Pointer(ptr)), math.Float32bits(new)))
+}
+
+// CompareAndSwapFloat32 executes the compare-and-swap operation for a float32
+// value. The values are compared by their bit patterns.
+func CompareAndSwapFloat32(ptr *float32, old, new float32) (swapped bool) {
+	return atomic.CompareAndSwapUint32((*uint32)(unsafe.Pointer(ptr)), math.Float32bits(old), math.Float32bits(new))
+}
+
+// LoadFloat64 atomically loads *ptr.
+func LoadFloat64(ptr *float64) float64 {
+	checkAligned64(ptr)
+	return math.Float64frombits(atomic.LoadUint64((*uint64)(unsafe.Pointer(ptr))))
+}
+
+// AddFloat64 atomically adds delta to *ptr and returns the new value.
+func AddFloat64(ptr *float64, delta float64) (new float64) {
+	checkAligned64(ptr)
+	p := (*uint64)(unsafe.Pointer(ptr))
+	for {
+		o := atomic.LoadUint64(p)
+		n := math.Float64bits(math.Float64frombits(o) + delta)
+		if atomic.CompareAndSwapUint64(p, o, n) {
+			return math.Float64frombits(n)
+		}
+	}
+}
+
+// StoreFloat64 atomically stores val into *ptr.
+func StoreFloat64(ptr *float64, val float64) {
+	checkAligned64(ptr)
+	atomic.StoreUint64((*uint64)(unsafe.Pointer(ptr)), math.Float64bits(val))
+}
+
+// SwapFloat64 atomically stores new into *ptr and returns the previous value.
+func SwapFloat64(ptr *float64, new float64) (old float64) {
+	checkAligned64(ptr)
+	return math.Float64frombits(atomic.SwapUint64((*uint64)(unsafe.Pointer(ptr)), math.Float64bits(new)))
+}
+
+// CompareAndSwapFloat64 executes the compare-and-swap operation for a float64
+// value. The values are compared by their bit patterns.
+func CompareAndSwapFloat64(ptr *float64, old, new float64) (swapped bool) {
+	checkAligned64(ptr)
+	return atomic.CompareAndSwapUint64((*uint64)(unsafe.Pointer(ptr)), math.Float64bits(old), math.Float64bits(new))
+}
@@ -3,6 +3,7 @@ package atomic_float
 import (
 	"math"
 	"math/big"
+	"math/rand"
 	"runtime"
 	"testing"
 )
@@ -13,6 +14,20 @@ func TestConvert(t *testing.T) {
 	bf.SetFloat64(f)
 }
 
+func TestNewFloat32(t *testing.T) {
+	f := NewFloat32(-1.5)
+	if result := f.Load(); result != -1.5 {
+		t.Errorf("Expected %v, got %v", -1.5, result)
+	}
+	if result := f.Bits(); result != math.Float32bits(-1.5) {
+		t.Errorf("Expected %#x, got %#x", math.Float32bits(-1.5), result)
+	}
+	nan := Float32FromBits(0x7fc0_beef)
+	if result := nan.Bits(); result != 0x7fc0_beef {
+		t.Errorf("Expected %#x, got %#x", 0x7fc0_beef, result)
+	}
+}
+
 // TestAddFloat32_Positive checks if adding a positive number to a Float32 works as expected.
 func TestAddFloat32_Positive(t *testing.T) {
 	var f Float32
@@ -79,6 +94,196 @@ func TestAddFloat32_Infinity(t *testing.T) {
 	}
 }
 
+// fmaFloat32Impls are the FMAFloat32 implementations under test. The
+// amd64 tests add the assembly one when the CPU supports it.
+var fmaFloat32Impls = map[string]func(ptr *float32, a, b float32) float32{
+	"FMAFloat32":    FMAFloat32,
+	"casFMAFloat32": casFMAFloat32,
+}
+
+// fmaFloat32Ref returns a*b + c rounded once to float32, computed exactly
+// with big.Float and rounded by big.Float.Float32, independently of math.FMA.
+func fmaFloat32Ref(a, b, c float32) float32 {
+	x := new(big.Float).SetPrec(2000).SetFloat64(float64(a))
+	x.Mul(x, new(big.Float).SetFloat64(float64(b)))
+	x.Add(x, new(big.Float).SetFloat64(float64(c)))
+	f, _ := x.Float32()
+	return f
+}
+
+// TestFMAFloat32 checks that FMA rounds once in every implementation,
+// including sums that rounding through float64 would round twice.
+func TestFMAFloat32(t *testing.T) {
+	ulp := float32(1.0 / (1 << 23))
+	tests := []struct {
+		a, b, c float32
+	}{
+		// a*b + c is distinguishable from a separately rounded multiply-add.
+		{1 + 1.0/(1<<13), 1 - 1.0/(1<<13), -1},
+		// a*b = 2^-24 - 2^-70, so the exact sum is just below the midpoint
+		// 1 + 3*2^-24, and float64 rounds it onto the midpoint, which then
+		// rounds to even, up.
+		{1 + ulp, (1 - ulp) / (1 << 24), 1 + ulp},
+		{-1 - ulp, (1 - ulp) / (1 << 24), -1 - ulp},
+		// Just above the midpoint 1 + 2^-24, which rounds to even, down.
+		{1 + ulp, (1 + ulp) / (1 << 24), 1},
+		// Near the overflow threshold and among subnormals.
+		{math.MaxFloat32, 1 + ulp, -math.MaxFloat32 / 2},
+		{0x1p-75, 0x1p-75, 3 * 0x1p-149},
+		{float32(math.Inf(1)), 1, 1},
+	}
+	r := rand.New(rand.NewSource(3))
+	for i := 0; i < 2000; i++ {
+		// (1 + k*ulp) * (1 - k*ulp) * 2^-24 is just below half an ulp of
+		// c in [1, 2), below by less than float64 can resolve, so all of
+		// these round twice through float64 when c's last bit is odd.
+		k := float32(r.Intn(255) + 1)
+		e := float32(math.Ldexp(1, r.Intn(100)-50))
+		c := (1 + float32(r.Intn(1<<22)*2+1)*ulp) * e
+		if r.Intn(2) == 0 {
+			c = -c
+		}
+		tests = append(tests, struct{ a, b, c float32 }{(1 + k*ulp) * e, (1 - k*ulp) / (1 << 24) * float32(math.Copysign(1, float64(c))), c})
+	}
+	for i := 0; i < 2000; i++ {
+		x := r.Float32() * float32(math.Ldexp(1, r.Intn(60)-30))
+		y := r.Float32() * float32(math.Ldexp(1, r.Intn(60)-30))
+		tests = append(tests, struct{ a, b, c float32 }{x, y, -x*y + float32(r.NormFloat64())*ulp*x*y})
+	}
+	for _, tt := range tests {
+		want := fmaFloat32Ref(tt.a, tt.b, tt.c)
+		if math.IsInf(float64(tt.a), 0) {
+			want = tt.a
+		}
+		for name, fma := range fmaFloat32Impls {
+			f := tt.c
+			if result := fma(&f, tt.a, tt.b); math.Float32bits(result) != math.Float32bits(want) {
+				t.Errorf("%s(%v, %v, %v): Expected %v, got %v", name, tt.a, tt.b, tt.c, want, result)
+			}
+		}
+	}
+	if want := fmaFloat32Ref(1+ulp, (1-ulp)/(1<<24), 1+ulp); want == float32(math.FMA(float64(1+ulp), float64((1-ulp)/(1<<24)), float64(1+ulp))) {
+		t.Fatalf("%v is not distinguishable from rounding through float64", want)
+	}
+
+	var f Float32
+	f.Store(-1)
+	a, b := float32(1+1.0/(1<<13)), float32(1-1.0/(1<<13))
+	if want, result := fmaFloat32Ref(a, b, -1), f.FMA(a, b); result != want {
+		t.Errorf("Expected %v, got %v", want, result)
+	}
+}
+
+func TestAddIfWithinFloat32(t *testing.T) {
+	var f Float32
+	if result, ok := f.AddIfWithin(1.5, 0, 2); !ok || result != 1.5 {
+		t.Errorf("Expected %v, true, got %v, %v", 1.5, result, ok)
+	}
+	if result, ok := f.AddIfWithin(1, 0, 2); ok || result != 1.5 {
+		t.Errorf("Expected %v, false, got %v, %v", 1.5, result, ok)
+	}
+	if result, ok := f.AddIfWithin(-2, 0, 2); ok || result != 1.5 {
+		t.Errorf("Expected %v, false, got %v, %v", 1.5, result, ok)
+	}
+	if result, ok := f.AddIfWithin(0.5, 0, 2); !ok || result != 2 {
+		t.Errorf("Expected %v, true, got %v, %v", 2, result, ok)
+	}
+	if result, ok := f.AddIfWithin(float32(math.NaN()), 0, 2); ok || result != 2 {
+		t.Errorf("Expected %v, false, got %v, %v", 2, result, ok)
+	}
+}
+
+func TestAddClampedFloat32(t *testing.T) {
+	var f Float32
+	if result := f.AddClamped(1.5, -1, 2); result != 1.5 {
+		t.Errorf("Expected %v, got %v", 1.5, result)
+	}
+	if result := f.AddClamped(1, -1, 2); result != 2 {
+		t.Errorf("Expected %v, got %v", 2, result)
+	}
+	if result := f.AddClamped(-10, -1, 2); result != -1 {
+		t.Errorf("Expected %v, got %v", -1, result)
+	}
+	if result := f.Load(); result != -1 {
+		t.Errorf("Expected %v, got %v", -1, result)
+	}
+}
+
+func TestAddClampedFloat32_NaN(t *testing.T) {
+	var f Float32
+	f.Store(1)
+	nan := float32(math.NaN())
+	if result := f.AddClamped(nan, -1, 2); result != 1 {
+		t.Errorf("Expected %v, got %v", 1, result)
+	}
+	f.Store(float32(math.Inf(1)))
+	if result := f.AddClamped(float32(math.Inf(-1)), -1, 2); !math.IsInf(float64(result), 1) {
+		t.Errorf("Expected %v, got %v", math.Inf(1), result)
+	}
+	if result := f.Load(); !math.IsInf(float64(result), 1) {
+		t.Errorf("Expected %v, got %v", math.Inf(1), result)
+	}
+
+	for _, bounds := range [][2]float32{{2, -1}, {nan, 1}, {-1, nan}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected panic for bounds %v", bounds)
+				}
+			}()
+			f.AddClamped(1, bounds[0], bounds[1])
+		}()
+	}
+}
+
+func TestSignFloat32(t *testing.T) {
+	var f Float32
+	f.Store(1.5)
+	if result := f.Negate(); result != 1.5 {
+		t.Errorf("Expected %v, got %v", 1.5, result)
+	}
+	if result := f.Load(); result != -1.5 {
+		t.Errorf("Expected %v, got %v", -1.5, result)
+	}
+	if result := f.Abs(); result != -1.5 {
+		t.Errorf("Expected %v, got %v", -1.5, result)
+	}
+	if result := f.SetSign(true); result != 1.5 {
+		t.Errorf("Expected %v, got %v", 1.5, result)
+	}
+	if result := f.CopySign(2); result != -1.5 {
+		t.Errorf("Expected %v, got %v", -1.5, result)
+	}
+	if result := f.Load(); result != 1.5 {
+		t.Errorf("Expected %v, got %v", 1.5, result)
+	}
+}
+
+// TestStepFloat32 checks Increment and Decrement against math.Nextafter32.
+func TestStepFloat32(t *testing.T) {
+	inf := float32(math.Inf(1))
+	values := []float32{
+		0, float32(math.Copysign(0, -1)), math.SmallestNonzeroFloat32, -math.SmallestNonzeroFloat32,
+		1, -1, 0.1, -1e-40, math.MaxFloat32, -math.MaxFloat32, inf, -inf,
+	}
+	for _, v := range values {
+		f := NewFloat32(v)
+		want := math.Nextafter32(v, inf)
+		if result := f.Increment(); math.Float32bits(result) != math.Float32bits(want) {
+			t.Errorf("Increment(%v): expected %v, got %v", v, want, result)
+		}
+		f.Store(v)
+		want = math.Nextafter32(v, -inf)
+		if result := f.Decrement(); math.Float32bits(result) != math.Float32bits(want) {
+			t.Errorf("Decrement(%v): expected %v, got %v", v, want, result)
+		}
+	}
+	nan := Float32FromBits(0x7fc0_beef)
+	if result := math.Float32bits(nan.Increment()); result != 0x7fc0_beef {
+		t.Errorf("Expected NaN %#x to be unchanged, got %#x", 0x7fc0_beef, result)
+	}
+}
+
 func TestStoreFloat32_Positive(t *testing.T) {
 	var f Float32
 	f.Store(1.2)
@@ -103,6 +308,21 @@ func TestStoreFloat32_Negative(t *testing.T) {
 	}
 }
 
+func TestOrderedFloat32(t *testing.T) {
+	var f Float32
+	f.StoreRelease(1.2)
+	if result := f.LoadAcquire(); result != 1.2 {
+		t.Errorf("Expected %v, got %v", 1.2, result)
+	}
+	f.StoreRelaxed(-2.3)
+	if result := f.LoadRelaxed(); result != -2.3 {
+		t.Errorf("Expected %v, got %v", -2.3, result)
+	}
+	if result := f.Load(); result != -2.3 {
+		t.Errorf("Expected %v, got %v", -2.3, result)
+	}
+}
+
 func TestSwapFloat32_Positive(t *testing.T) {
 	var f Float32
 	if result := f.Swap(1.2); result != 0.0 {
@@ -181,6 +401,47 @@ func TestAddFloat32Concurrent(t *testing.T) {
 	runtime.GC()
 }
 
+func TestNewFloat64(t *testing.T) {
+	f := NewFloat64(-1.5)
+	if result := f.Load(); result != -1.5 {
+		t.Errorf("Expected %v, got %v", -1.5, result)
+	}
+	if result := f.Add(1); result != -0.5 {
+		t.Errorf("Expected %v, got %v", -0.5, result)
+	}
+	if result := f.Bits(); result != math.Float64bits(-0.5) {
+		t.Errorf("Expected %#x, got %#x", math.Float64bits(-0.5), result)
+	}
+	nan := Float64FromBits(0x7ff8_0000_dead_beef)
+	if result := nan.Bits(); result != 0x7ff8_0000_dead_beef {
+		t.Errorf("Expected %#x, got %#x", uint64(0x7ff8_0000_dead_beef), result)
+	}
+	negZero := Float64FromBits(1 << 63)
+	if result := negZero.Load(); result != 0 || !math.Signbit(result) {
+		t.Errorf("Expected %v, got %v", math.Copysign(0, -1), result)
+	}
+}
+
+// TestNewFloat64Publish checks that a reader receiving the pointer from
+// NewFloat64 always sees the initial value.
+func TestNewFloat64Publish(t *testing.T) {
+	ch := make(chan *Float64)
+	done := make(chan bool)
+	go func() {
+		for f := range ch {
+			if result := f.Load(); result != 42 {
+				t.Errorf("Expected %v, got %v", 42, result)
+			}
+		}
+		done <- true
+	}()
+	for i := 0; i < 1000; i++ {
+		ch <- NewFloat64(42)
+	}
+	close(ch)
+	<-done
+}
+
 // TestAddFloat64_Positive checks if adding a positive number to a Float64 works as expected.
 func TestAddFloat64_Positive(t *testing.T) {
 	var f Float64
@@ -247,6 +508,297 @@ func TestAddFloat64_Infinity(t *testing.T) {
 	}
 }
 
+// fmaFloat64Impls are the FMAFloat64 implementations under test. The
+// amd64 tests add the assembly one when the CPU supports it.
+var fmaFloat64Impls = map[string]func(ptr *float64, a, b float64) float64{
+	"FMAFloat64":    FMAFloat64,
+	"casFMAFloat64": casFMAFloat64,
+}
+
+// TestFMAFloat64 checks that FMA rounds once, like math.FMA, in every implementation.
+func TestFMAFloat64(t *testing.T) {
+	a := 1 + 1.0/(1<<30)
+	b := 1 - 1.0/(1<<30)
+	want := math.FMA(a, b, -1)
+	if want == float64(a*b)-1 {
+		t.Fatalf("%v is not distinguishable from a separately rounded multiply-add", want)
+	}
+	for name, fma := range fmaFloat64Impls {
+		f := float64(-1)
+		if result := fma(&f, a, b); result != want {
+			t.Errorf("%s: Expected %v, got %v", name, want, result)
+		}
+		if f != want {
+			t.Errorf("%s: Expected %v, got %v", name, want, f)
+		}
+	}
+	var f Float64
+	f.Store(-1)
+	if result := f.FMA(a, b); result != want {
+		t.Errorf("Expected %v, got %v", want, result)
+	}
+}
+
+func TestFMAFloat64Concurrent(t *testing.T) {
+	const itemsCount = 10000
+	const gorotines = 10
+	var f Float64
+
+	done := make(chan bool)
+	for i := 0; i < gorotines; i++ {
+		go func() {
+			for j := 0; j < itemsCount; j++ {
+				f.FMA(0.5, 3)
+			}
+			done <- true
+		}()
+	}
+	for i := 0; i < gorotines; i++ {
+		<-done
+	}
+	if result := f.Load(); result != float64(itemsCount*gorotines)*1.5 {
+		t.Errorf("Expected %v, got %v", float64(itemsCount*gorotines)*1.5, result)
+	}
+}
+
+func TestAddIfWithinFloat64(t *testing.T) {
+	var f Float64
+	if result, ok := f.AddIfWithin(1.5, 0, 2); !ok || result != 1.5 {
+		t.Errorf("Expected %v, true, got %v, %v", 1.5, result, ok)
+	}
+	if result, ok := f.AddIfWithin(1, 0, 2); ok || result != 1.5 {
+		t.Errorf("Expected %v, false, got %v, %v", 1.5, result, ok)
+	}
+	if result, ok := f.AddIfWithin(-2, 0, 2); ok || result != 1.5 {
+		t.Errorf("Expected %v, false, got %v, %v", 1.5, result, ok)
+	}
+	if result, ok := f.AddIfWithin(0.5, 0, 2); !ok || result != 2 {
+		t.Errorf("Expected %v, true, got %v, %v", 2, result, ok)
+	}
+	if result, ok := f.AddIfWithin(math.NaN(), 0, 2); ok || result != 2 {
+		t.Errorf("Expected %v, false, got %v, %v", 2, result, ok)
+	}
+}
+
+// TestAddIfWithinFloat64Concurrent checks that concurrent quota reservations never overshoot the limit.
+func TestAddIfWithinFloat64Concurrent(t *testing.T) {
+	const itemsCount = 10000
+	const gorotines = 10
+	const limit = 12345.0
+	var f Float64
+
+	accepted := make(chan int)
+	for i := 0; i < gorotines; i++ {
+		go func() {
+			n := 0
+			for j := 0; j < itemsCount; j++ {
+				if _, ok := f.AddIfWithin(1, 0, limit); ok {
+					n++
+				}
+			}
+			accepted <- n
+		}()
+	}
+	total := 0
+	for i := 0; i < gorotines; i++ {
+		total += <-accepted
+	}
+	if total != limit {
+		t.Errorf("Expected %v accepted additions, got %v", limit, total)
+	}
+	if result := f.Load(); result != limit {
+		t.Errorf("Expected %v, got %v", limit, result)
+	}
+}
+
+func TestAddClampedFloat64(t *testing.T) {
+	var f Float64
+	if result := f.AddClamped(1.5, -1, 2); result != 1.5 {
+		t.Errorf("Expected %v, got %v", 1.5, result)
+	}
+	if result := f.AddClamped(1, -1, 2); result != 2 {
+		t.Errorf("Expected %v, got %v", 2, result)
+	}
+	if result := f.AddClamped(-10, -1, 2); result != -1 {
+		t.Errorf("Expected %v, got %v", -1, result)
+	}
+	if result := f.Load(); result != -1 {
+		t.Errorf("Expected %v, got %v", -1, result)
+	}
+}
+
+func TestAddClampedFloat64_NaN(t *testing.T) {
+	var f Float64
+	f.Store(1)
+	nan := float64(math.NaN())
+	if result := f.AddClamped(nan, -1, 2); result != 1 {
+		t.Errorf("Expected %v, got %v", 1, result)
+	}
+	f.Store(float64(math.Inf(1)))
+	if result := f.AddClamped(float64(math.Inf(-1)), -1, 2); !math.IsInf(float64(result), 1) {
+		t.Errorf("Expected %v, got %v", math.Inf(1), result)
+	}
+	if result := f.Load(); !math.IsInf(float64(result), 1) {
+		t.Errorf("Expected %v, got %v", math.Inf(1), result)
+	}
+
+	for _, bounds := range [][2]float64{{2, -1}, {nan, 1}, {-1, nan}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected panic for bounds %v", bounds)
+				}
+			}()
+			f.AddClamped(1, bounds[0], bounds[1])
+		}()
+	}
+}
+
+func TestSignFloat64(t *testing.T) {
+	var f Float64
+	f.Store(1.5)
+	if result := f.Negate(); result != 1.5 {
+		t.Errorf("Expected %v, got %v", 1.5, result)
+	}
+	if result := f.Load(); result != -1.5 {
+		t.Errorf("Expected %v, got %v", -1.5, result)
+	}
+	if result := f.Abs(); result != -1.5 {
+		t.Errorf("Expected %v, got %v", -1.5, result)
+	}
+	if result := f.Abs(); result != 1.5 {
+		t.Errorf("Expected %v, got %v", 1.5, result)
+	}
+	if result := f.SetSign(true); result != 1.5 {
+		t.Errorf("Expected %v, got %v", 1.5, result)
+	}
+	if result := f.SetSign(true); result != -1.5 {
+		t.Errorf("Expected %v, got %v", -1.5, result)
+	}
+	if result := f.CopySign(math.Copysign(0, 1)); result != -1.5 {
+		t.Errorf("Expected %v, got %v", -1.5, result)
+	}
+	if result := f.Load(); result != 1.5 {
+		t.Errorf("Expected %v, got %v", 1.5, result)
+	}
+}
+
+// TestSignFloat64_Special checks that sign operations only touch the sign bit.
+func TestSignFloat64_Special(t *testing.T) {
+	for _, bits := range []uint64{0, 1, 0x7ff0_0000_0000_0000, 0x7ff8_0000_dead_beef, 0xfff0_0000_0000_0000} {
+		f := Float64FromBits(bits)
+		if result := math.Float64bits(f.Negate()); result != bits {
+			t.Errorf("Expected %#x, got %#x", bits, result)
+		}
+		if result := f.Bits(); result != bits^1<<63 {
+			t.Errorf("Expected %#x, got %#x", bits^1<<63, result)
+		}
+		f.Abs()
+		if result := f.Bits(); result != bits&^(1<<63) {
+			t.Errorf("Expected %#x, got %#x", bits&^(1<<63), result)
+		}
+		f.SetSign(true)
+		if result := f.Bits(); result != bits|1<<63 {
+			t.Errorf("Expected %#x, got %#x", bits|1<<63, result)
+		}
+	}
+}
+
+func TestNegateFloat64Concurrent(t *testing.T) {
+	const itemsCount = 10000
+	const gorotines = 10
+	var f Float64
+	f.Store(2.5)
+
+	done := make(chan bool)
+	for i := 0; i < gorotines; i++ {
+		go func() {
+			for j := 0; j < itemsCount; j++ {
+				f.Negate()
+			}
+			done <- true
+		}()
+	}
+	for i := 0; i < gorotines; i++ {
+		<-done
+	}
+	if result := f.Load(); result != 2.5 {
+		t.Errorf("Expected %v, got %v", 2.5, result)
+	}
+}
+
+// TestStepFloat64 checks Increment and Decrement against math.Nextafter.
+func TestStepFloat64(t *testing.T) {
+	inf := math.Inf(1)
+	values := []float64{
+		0, math.Copysign(0, -1), math.SmallestNonzeroFloat64, -math.SmallestNonzeroFloat64,
+		1, -1, 0.1, -1e-310, 0x1p-1022, -0x1p-1022, math.MaxFloat64, -math.MaxFloat64, inf, -inf,
+	}
+	for _, v := range values {
+		f := NewFloat64(v)
+		want := math.Nextafter(v, inf)
+		if result := f.Increment(); math.Float64bits(result) != math.Float64bits(want) {
+			t.Errorf("Increment(%v): expected %v, got %v", v, want, result)
+		}
+		if result := f.Load(); math.Float64bits(result) != math.Float64bits(want) {
+			t.Errorf("Increment(%v): expected %v stored, got %v", v, want, result)
+		}
+		f.Store(v)
+		want = math.Nextafter(v, -inf)
+		if result := f.Decrement(); math.Float64bits(result) != math.Float64bits(want) {
+			t.Errorf("Decrement(%v): expected %v, got %v", v, want, result)
+		}
+	}
+	for _, bits := range []uint64{0x7ff8_0000_dead_beef, 0xfff8_0000_0000_0001} {
+		nan := Float64FromBits(bits)
+		nan.Increment()
+		nan.Decrement()
+		if result := nan.Bits(); result != bits {
+			t.Errorf("Expected NaN %#x to be unchanged, got %#x", bits, result)
+		}
+	}
+}
+
+// TestStepFloat64_Walk steps across zero through the subnormals one ULP at a time.
+func TestStepFloat64_Walk(t *testing.T) {
+	v := -3 * math.SmallestNonzeroFloat64
+	f := NewFloat64(v)
+	for i := 0; i < 6; i++ {
+		v = math.Nextafter(v, 1)
+		if result := f.Increment(); math.Float64bits(result) != math.Float64bits(v) {
+			t.Fatalf("Step %d: expected %v, got %v", i, v, result)
+		}
+	}
+	for i := 0; i < 6; i++ {
+		v = math.Nextafter(v, -1)
+		if result := f.Decrement(); math.Float64bits(result) != math.Float64bits(v) {
+			t.Fatalf("Step %d: expected %v, got %v", i, v, result)
+		}
+	}
+}
+
+func TestIncrementFloat64Concurrent(t *testing.T) {
+	const itemsCount = 10000
+	const gorotines = 10
+	f := NewFloat64(1)
+
+	done := make(chan bool)
+	for i := 0; i < gorotines; i++ {
+		go func() {
+			for j := 0; j < itemsCount; j++ {
+				f.Increment()
+			}
+			done <- true
+		}()
+	}
+	for i := 0; i < gorotines; i++ {
+		<-done
+	}
+	if result := f.Bits(); result != math.Float64bits(1)+itemsCount*gorotines {
+		t.Errorf("Expected %#x, got %#x", math.Float64bits(1)+itemsCount*gorotines, result)
+	}
+}
+
 func TestStoreFloat64_Positive(t *testing.T) {
 	var f Float64
 	f.Store(1.2)
@@ -271,6 +823,50 @@ func TestStoreFloat64_Negative(t *testing.T) {
 	}
 }
 
+func TestOrderedFloat64(t *testing.T) {
+	var f Float64
+	f.StoreRelease(1.2)
+	if result := f.LoadAcquire(); result != 1.2 {
+		t.Errorf("Expected %v, got %v", 1.2, result)
+	}
+	f.StoreRelaxed(-2.3)
+	if result := f.LoadRelaxed(); result != -2.3 {
+		t.Errorf("Expected %v, got %v", -2.3, result)
+	}
+	if result := f.Load(); result != -2.3 {
+		t.Errorf("Expected %v, got %v", -2.3, result)
+	}
+}
+
+// TestReleaseAcquireFloat64 passes data through a relaxed store published by
+// a release store, and checks that an acquire load of the flag makes the data visible.
+func TestReleaseAcquireFloat64(t *testing.T) {
+	const rounds = 10000
+	var data, flag, ack Float64
+
+	done := make(chan bool)
+	go func() {
+		for i := 1; i <= rounds; i++ {
+			for flag.LoadAcquire() != float64(i) {
+				runtime.Gosched()
+			}
+			if result := data.LoadRelaxed(); result != float64(i)*1.5 {
+				t.Errorf("Expected %v, got %v", float64(i)*1.5, result)
+			}
+			ack.StoreRelease(float64(i))
+		}
+		done <- true
+	}()
+	for i := 1; i <= rounds; i++ {
+		data.StoreRelaxed(float64(i) * 1.5)
+		flag.StoreRelease(float64(i))
+		for ack.LoadAcquire() != float64(i) {
+			runtime.Gosched()
+		}
+	}
+	<-done
+}
+
 func TestSwapFloat64_Positive(t *testing.T) {
 	var f Float64
 	if result := f.Swap(1.2); result != 0.0 {
@@ -348,3 +944,65 @@ func TestAddFloat64Concurrent(t *testing.T) {
 	}
 	runtime.GC()
 }
+
+// TestAdjacentFieldsFloat32 checks that no operation writes past the float32
+// it is given into neighbouring struct fields.
+func TestAdjacentFieldsFloat32(t *testing.T) {
+	const canary = 0xa5a5a5a5
+	ops := map[string]func(p *float32){
+		"Add":            func(p *float32) { AddFloat32(p, 1) },
+		"Store":          func(p *float32) { StoreFloat32(p, -1) },
+		"StoreRelease":   func(p *float32) { StoreReleaseFloat32(p, -1) },
+		"StoreRelaxed":   func(p *float32) { StoreRelaxedFloat32(p, -1) },
+		"Swap":           func(p *float32) { SwapFloat32(p, -1) },
+		"CompareAndSwap": func(p *float32) { CompareAndSwapFloat32(p, 1.5, -1) },
+		"FMA":            func(p *float32) { FMAFloat32(p, 2, 3) },
+		"Negate":         func(p *float32) { NegateFloat32(p) },
+		"Abs":            func(p *float32) { AbsFloat32(p) },
+		"SetSign":        func(p *float32) { SetSignFloat32(p, true) },
+		"Increment":      func(p *float32) { IncrementFloat32(p) },
+		"Decrement":      func(p *float32) { DecrementFloat32(p) },
+	}
+	for name, op := range ops {
+		s := struct {
+			lo uint32
+			v  float32
+			hi uint32
+		}{canary, 1.5, canary}
+		op(&s.v)
+		if s.lo != canary || s.hi != canary {
+			t.Errorf("%s: Expected canaries %#x, got %#x and %#x", name, uint32(canary), s.lo, s.hi)
+		}
+	}
+}
+
+// TestAdjacentFieldsFloat64 checks that no operation writes past the float64
+// it is given into neighbouring struct fields.
+func TestAdjacentFieldsFloat64(t *testing.T) {
+	const canary = 0xa5a5a5a5_a5a5a5a5
+	ops := map[string]func(p *float64){
+		"Add":            func(p *float64) { AddFloat64(p, 1) },
+		"Store":          func(p *float64) { StoreFloat64(p, -1) },
+		"StoreRelease":   func(p *float64) { StoreReleaseFloat64(p, -1) },
+		"StoreRelaxed":   func(p *float64) { StoreRelaxedFloat64(p, -1) },
+		"Swap":           func(p *float64) { SwapFloat64(p, -1) },
+		"CompareAndSwap": func(p *float64) { CompareAndSwapFloat64(p, 1.5, -1) },
+		"FMA":            func(p *float64) { FMAFloat64(p, 2, 3) },
+		"Negate":         func(p *float64) { NegateFloat64(p) },
+		"Abs":            func(p *float64) { AbsFloat64(p) },
+		"SetSign":        func(p *float64) { SetSignFloat64(p, true) },
+		"Increment":      func(p *float64) { IncrementFloat64(p) },
+		"Decrement":      func(p *float64) { DecrementFloat64(p) },
+	}
+	for name, op := range ops {
+		s := struct {
+			lo uint64
+			v  float64
+			hi uint64
+		}{canary, 1.5, canary}
+		op(&s.v)
+		if s.lo != canary || s.hi != canary {
+			t.Errorf("%s: Expected canaries %#x, got %#x and %#x", name, uint64(canary), s.lo, s.hi)
+		}
+	}
+}
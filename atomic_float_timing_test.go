@@ -5,6 +5,28 @@ import (
 	"testing"
 )
 
+func BenchmarkLoadFloat32(b *testing.B) {
+	var x Float32
+	x.Store(2.5)
+	var sum float32
+	for i := 0; i < b.N; i++ {
+		sum += x.Load()
+	}
+	_ = sum
+}
+
+func BenchmarkLoadFloat32Parallel(b *testing.B) {
+	var x Float32
+	x.Store(2.5)
+	b.RunParallel(func(pb *testing.PB) {
+		var sum float32
+		for pb.Next() {
+			sum += x.Load()
+		}
+		_ = sum
+	})
+}
+
 func BenchmarkAddFloat32(b *testing.B) {
 	var x Float32
 	var y float32 = 2.5
@@ -95,6 +117,46 @@ func BenchmarkStoreFloat32Parallel_Mutex(b *testing.B) {
 	})
 }
 
+func BenchmarkStoreReleaseFloat32(b *testing.B) {
+	var x Float32
+	for i := 0; i < b.N; i++ {
+		x.StoreRelease(float32(i))
+		if res := x.LoadAcquire(); res != float32(i) {
+			b.Errorf("Expected %v, got %v", float32(i), res)
+		}
+	}
+}
+
+func BenchmarkStoreReleaseFloat32Parallel(b *testing.B) {
+	var x Float32
+	var delta float32 = 1.1
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			x.StoreRelease(delta)
+		}
+	})
+}
+
+func BenchmarkStoreRelaxedFloat32(b *testing.B) {
+	var x Float32
+	for i := 0; i < b.N; i++ {
+		x.StoreRelaxed(float32(i))
+		if res := x.LoadRelaxed(); res != float32(i) {
+			b.Errorf("Expected %v, got %v", float32(i), res)
+		}
+	}
+}
+
+func BenchmarkStoreRelaxedFloat32Parallel(b *testing.B) {
+	var x Float32
+	var delta float32 = 1.1
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			x.StoreRelaxed(delta)
+		}
+	})
+}
+
 func BenchmarkSwapFloat32(b *testing.B) {
 	var x Float32
 	for i := 1; i < b.N; i++ {
@@ -187,6 +249,28 @@ func BenchmarkCASFloat32Parallel_Mutex(b *testing.B) {
 		}
 	})
 }
+func BenchmarkLoadFloat64(b *testing.B) {
+	var x Float64
+	x.Store(2.5)
+	var sum float64
+	for i := 0; i < b.N; i++ {
+		sum += x.Load()
+	}
+	_ = sum
+}
+
+func BenchmarkLoadFloat64Parallel(b *testing.B) {
+	var x Float64
+	x.Store(2.5)
+	b.RunParallel(func(pb *testing.PB) {
+		var sum float64
+		for pb.Next() {
+			sum += x.Load()
+		}
+		_ = sum
+	})
+}
+
 func BenchmarkAddFloat64(b *testing.B) {
 	var x Float64
 	var y float64 = 2.5
@@ -195,6 +279,32 @@ func BenchmarkAddFloat64(b *testing.B) {
 	}
 }
 
+func BenchmarkAddWatchableFloat64(b *testing.B) {
+	var x WatchableFloat64
+	var y float64 = 2.5
+	for i := 0; i < b.N; i++ {
+		x.Add(y)
+	}
+}
+
+func BenchmarkAddExactSum(b *testing.B) {
+	var x ExactSum
+	var y float64 = 2.5
+	for i := 0; i < b.N; i++ {
+		x.Add(y)
+	}
+}
+
+func BenchmarkAddExactSumParallel(b *testing.B) {
+	var x ExactSum
+	var delta float64 = 2.5
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			x.Add(delta)
+		}
+	})
+}
+
 func BenchmarkAddFloat64_Mutex(b *testing.B) {
 	var x float64
 	mf := newAtomicFloat64Mutex(x)
@@ -267,6 +377,46 @@ func BenchmarkStoreFloat64Parallel_Mutex(b *testing.B) {
 	})
 }
 
+func BenchmarkStoreReleaseFloat64(b *testing.B) {
+	var x Float64
+	for i := 0; i < b.N; i++ {
+		x.StoreRelease(float64(i))
+		if res := x.LoadAcquire(); res != float64(i) {
+			b.Errorf("Expected %v, got %v", float64(i), res)
+		}
+	}
+}
+
+func BenchmarkStoreReleaseFloat64Parallel(b *testing.B) {
+	var x Float64
+	var delta float64 = 1.1
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			x.StoreRelease(delta)
+		}
+	})
+}
+
+func BenchmarkStoreRelaxedFloat64(b *testing.B) {
+	var x Float64
+	for i := 0; i < b.N; i++ {
+		x.StoreRelaxed(float64(i))
+		if res := x.LoadRelaxed(); res != float64(i) {
+			b.Errorf("Expected %v, got %v", float64(i), res)
+		}
+	}
+}
+
+func BenchmarkStoreRelaxedFloat64Parallel(b *testing.B) {
+	var x Float64
+	var delta float64 = 1.1
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			x.StoreRelaxed(delta)
+		}
+	})
+}
+
 func BenchmarkSwapFloat64(b *testing.B) {
 	var x Float64
 	for i := 1; i < b.N; i++ {
@@ -359,3 +509,110 @@ func BenchmarkCASFloat64Parallel_Mutex(b *testing.B) {
 		}
 	})
 }
+
+// Each goroutine adds to its own counter. Without padding the counters share
+// cache lines and every Add invalidates the line for the other CPUs.
+func BenchmarkAddAdjacentFloat64Parallel(b *testing.B) {
+	var counters [8]Float64
+	var next atomic.Int32
+	b.RunParallel(func(pb *testing.PB) {
+		x := &counters[int(next.Add(1))%len(counters)]
+		for pb.Next() {
+			x.Add(1)
+		}
+	})
+}
+
+func BenchmarkAddAdjacentPaddedFloat64Parallel(b *testing.B) {
+	var counters [8]PaddedFloat64
+	var next atomic.Int32
+	b.RunParallel(func(pb *testing.PB) {
+		x := &counters[int(next.Add(1))%len(counters)]
+		for pb.Next() {
+			x.Add(1)
+		}
+	})
+}
+
+func BenchmarkLoadVec3(b *testing.B) {
+	var x AtomicVec3
+	x.Store(Vec3{1, 2, 3})
+	for i := 0; i < b.N; i++ {
+		if res := x.Load(); res.X != 1 {
+			b.Errorf("Expected %v, got %v", 1, res.X)
+		}
+	}
+}
+
+func BenchmarkLoadVec3_Mutex(b *testing.B) {
+	mv := newAtomicVec3Mutex(Vec3{1, 2, 3})
+	for i := 0; i < b.N; i++ {
+		if res := mv.load(); res.X != 1 {
+			b.Errorf("Expected %v, got %v", 1, res.X)
+		}
+	}
+}
+
+func BenchmarkLoadVec3Parallel(b *testing.B) {
+	var x AtomicVec3
+	x.Store(Vec3{1, 2, 3})
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			x.Load()
+		}
+	})
+}
+
+func BenchmarkLoadVec3Parallel_Mutex(b *testing.B) {
+	mv := newAtomicVec3Mutex(Vec3{1, 2, 3})
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			mv.load()
+		}
+	})
+}
+
+func BenchmarkStoreVec3(b *testing.B) {
+	var x AtomicVec3
+	for i := 0; i < b.N; i++ {
+		x.Store(Vec3{float64(i), 2, 3})
+	}
+}
+
+func BenchmarkStoreVec3_Mutex(b *testing.B) {
+	mv := newAtomicVec3Mutex(Vec3{})
+	for i := 0; i < b.N; i++ {
+		mv.store(Vec3{float64(i), 2, 3})
+	}
+}
+
+// One writer per benchmark, the remaining goroutines read.
+func BenchmarkLoadStoreVec3Parallel(b *testing.B) {
+	var x AtomicVec3
+	var writer atomic.Bool
+	b.RunParallel(func(pb *testing.PB) {
+		write := writer.CompareAndSwap(false, true)
+		for i := 0; pb.Next(); i++ {
+			if write {
+				x.Store(Vec3{float64(i), 2, 3})
+			} else {
+				x.Load()
+			}
+		}
+	})
+}
+
+func BenchmarkLoadStoreVec3Parallel_Mutex(b *testing.B) {
+	mv := newAtomicVec3Mutex(Vec3{})
+	var writer atomic.Bool
+	b.RunParallel(func(pb *testing.PB) {
+		write := writer.CompareAndSwap(false, true)
+		for i := 0; pb.Next(); i++ {
+			if write {
+				mv.store(Vec3{float64(i), 2, 3})
+			} else {
+				mv.load()
+			}
+		}
+	})
+}
@@ -0,0 +1,104 @@
+//go:build amd64 || 386
+
+package atomic_float
+
+import (
+	"math"
+	"testing"
+)
+
+var float32Edges = []float32{
+	0, float32(math.Copysign(0, -1)), 1, -1, 2.5, -2.5,
+	math.SmallestNonzeroFloat32, -math.SmallestNonzeroFloat32, math.MaxFloat32, -math.MaxFloat32,
+	float32(math.Inf(1)), float32(math.Inf(-1)), float32(math.NaN()),
+}
+
+var float64Edges = []float64{
+	0, math.Copysign(0, -1), 1, -1, 2.5, -2.5,
+	math.SmallestNonzeroFloat64, -math.SmallestNonzeroFloat64, math.MaxFloat64, -math.MaxFloat64,
+	math.Inf(1), math.Inf(-1), math.NaN(),
+}
+
+// TestAsmMatchesDefaultFloat32 checks that the assembly and the sync/atomic
+// implementations of the core operations agree bit for bit, whichever one
+// the build selected.
+func TestAsmMatchesDefaultFloat32(t *testing.T) {
+	type op func(ptr *float32, v float32) (float32, bool)
+	pairs := map[string][2]op{
+		"Load": {
+			func(p *float32, v float32) (float32, bool) { return asmLoadFloat32(p), false },
+			func(p *float32, v float32) (float32, bool) { return LoadFloat32(p), false },
+		},
+		"Add": {
+			func(p *float32, v float32) (float32, bool) { return asmAddFloat32(p, v), false },
+			func(p *float32, v float32) (float32, bool) { return AddFloat32(p, v), false },
+		},
+		"Store": {
+			func(p *float32, v float32) (float32, bool) { asmStoreFloat32(p, v); return 0, false },
+			func(p *float32, v float32) (float32, bool) { StoreFloat32(p, v); return 0, false },
+		},
+		"Swap": {
+			func(p *float32, v float32) (float32, bool) { return asmSwapFloat32(p, v), false },
+			func(p *float32, v float32) (float32, bool) { return SwapFloat32(p, v), false },
+		},
+		"CompareAndSwap": {
+			func(p *float32, v float32) (float32, bool) { return 0, asmCompareAndSwapFloat32(p, v, 1) },
+			func(p *float32, v float32) (float32, bool) { return 0, CompareAndSwapFloat32(p, v, 1) },
+		},
+	}
+	for name, pair := range pairs {
+		for _, x := range float32Edges {
+			for _, v := range float32Edges {
+				a, b := NewFloat32(x), NewFloat32(x)
+				ra, oka := pair[0](&a.v, v)
+				rb, okb := pair[1](&b.v, v)
+				if math.Float32bits(ra) != math.Float32bits(rb) || oka != okb || a.Bits() != b.Bits() {
+					t.Errorf("%s(%v, %v): asm gave %v, %v and left %v; default gave %v, %v and left %v",
+						name, x, v, ra, oka, a.Load(), rb, okb, b.Load())
+				}
+			}
+		}
+	}
+}
+
+// TestAsmMatchesDefaultFloat64 checks that the assembly and the sync/atomic
+// implementations of the core operations agree bit for bit, whichever one
+// the build selected.
+func TestAsmMatchesDefaultFloat64(t *testing.T) {
+	type op func(ptr *float64, v float64) (float64, bool)
+	pairs := map[string][2]op{
+		"Load": {
+			func(p *float64, v float64) (float64, bool) { return asmLoadFloat64(p), false },
+			func(p *float64, v float64) (float64, bool) { return LoadFloat64(p), false },
+		},
+		"Add": {
+			func(p *float64, v float64) (float64, bool) { return asmAddFloat64(p, v), false },
+			func(p *float64, v float64) (float64, bool) { return AddFloat64(p, v), false },
+		},
+		"Store": {
+			func(p *float64, v float64) (float64, bool) { asmStoreFloat64(p, v); return 0, false },
+			func(p *float64, v float64) (float64, bool) { StoreFloat64(p, v); return 0, false },
+		},
+		"Swap": {
+			func(p *float64, v float64) (float64, bool) { return asmSwapFloat64(p, v), false },
+			func(p *float64, v float64) (float64, bool) { return SwapFloat64(p, v), false },
+		},
+		"CompareAndSwap": {
+			func(p *float64, v float64) (float64, bool) { return 0, asmCompareAndSwapFloat64(p, v, 1) },
+			func(p *float64, v float64) (float64, bool) { return 0, CompareAndSwapFloat64(p, v, 1) },
+		},
+	}
+	for name, pair := range pairs {
+		for _, x := range float64Edges {
+			for _, v := range float64Edges {
+				a, b := NewFloat64(x), NewFloat64(x)
+				ra, oka := pair[0](&a.v, v)
+				rb, okb := pair[1](&b.v, v)
+				if math.Float64bits(ra) != math.Float64bits(rb) || oka != okb || a.Bits() != b.Bits() {
+					t.Errorf("%s(%v, %v): asm gave %v, %v and left %v; default gave %v, %v and left %v",
+						name, x, v, ra, oka, a.Load(), rb, okb, b.Load())
+				}
+			}
+		}
+	}
+}
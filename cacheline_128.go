@@ -0,0 +1,7 @@
+//go:build arm64 || ppc64 || ppc64le
+
+package atomic_float
+
+// See golang.org/x/sys/cpu.CacheLinePad
+
+const cacheLineSize = 128
@@ -0,0 +1,5 @@
+package atomic_float
+
+// See golang.org/x/sys/cpu.CacheLinePad
+
+const cacheLineSize = 256
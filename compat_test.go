@@ -0,0 +1,276 @@
+package atomic_float
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+// uberFloat64 and uberFloat32 are the method sets of go.uber.org/atomic's
+// Float64 and Float32, so that those types can be replaced by these.
+type uberFloat64 interface {
+	Load() float64
+	Store(val float64)
+	Add(delta float64) float64
+	Sub(delta float64) float64
+	CAS(old, new float64) bool
+	CompareAndSwap(old, new float64) bool
+	Swap(val float64) float64
+	MarshalJSON() ([]byte, error)
+	UnmarshalJSON(b []byte) error
+	String() string
+}
+
+type uberFloat32 interface {
+	Load() float32
+	Store(val float32)
+	Add(delta float32) float32
+	Sub(delta float32) float32
+	CAS(old, new float32) bool
+	CompareAndSwap(old, new float32) bool
+	Swap(val float32) float32
+	MarshalJSON() ([]byte, error)
+	UnmarshalJSON(b []byte) error
+	String() string
+}
+
+var (
+	_ uberFloat64 = NewFloat64(0)
+	_ uberFloat32 = NewFloat32(0)
+)
+
+// TestUberCompatFloat64 follows go.uber.org/atomic's TestFloat64.
+func TestUberCompatFloat64(t *testing.T) {
+	atom := NewFloat64(4.2)
+
+	if result := atom.Load(); result != 4.2 {
+		t.Errorf("Load didn't work. Expected %v, got %v", 4.2, result)
+	}
+
+	if !atom.CAS(4.2, 0.5) {
+		t.Errorf("CAS didn't report a swap.")
+	}
+	if result := atom.Load(); result != 0.5 {
+		t.Errorf("CAS didn't set the correct value. Expected %v, got %v", 0.5, result)
+	}
+	if atom.CAS(0.0, 1.5) {
+		t.Errorf("CAS reported a swap.")
+	}
+
+	atom.Store(42.0)
+	if result := atom.Load(); result != 42.0 {
+		t.Errorf("Store didn't set the correct value. Expected %v, got %v", 42.0, result)
+	}
+	if result := atom.Add(0.5); result != 42.5 {
+		t.Errorf("Add didn't work. Expected %v, got %v", 42.5, result)
+	}
+	if result := atom.Sub(0.5); result != 42.0 {
+		t.Errorf("Sub didn't work. Expected %v, got %v", 42.0, result)
+	}
+
+	if result := atom.Swap(45.0); result != 42.0 {
+		t.Errorf("Swap didn't return the old value. Expected %v, got %v", 42.0, result)
+	}
+	if result := atom.Load(); result != 45.0 {
+		t.Errorf("Swap didn't set the correct value. Expected %v, got %v", 45.0, result)
+	}
+
+	t.Run("JSON/Marshal", func(t *testing.T) {
+		atom.Store(42.5)
+		bytes, err := json.Marshal(atom)
+		if err != nil {
+			t.Fatalf("json.Marshal errored unexpectedly: %v", err)
+		}
+		if string(bytes) != "42.5" {
+			t.Errorf("json.Marshal encoded the wrong bytes. Expected %s, got %s", "42.5", bytes)
+		}
+	})
+
+	t.Run("JSON/Unmarshal", func(t *testing.T) {
+		if err := json.Unmarshal([]byte("40.5"), &atom); err != nil {
+			t.Fatalf("json.Unmarshal errored unexpectedly: %v", err)
+		}
+		if result := atom.Load(); result != 40.5 {
+			t.Errorf("json.Unmarshal didn't set the correct value. Expected %v, got %v", 40.5, result)
+		}
+	})
+
+	t.Run("JSON/Unmarshal/Error", func(t *testing.T) {
+		err := json.Unmarshal([]byte(`"40.5"`), &atom)
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			t.Errorf("json.Unmarshal failed with unexpected error %v, want UnmarshalTypeError.", err)
+		}
+	})
+
+	t.Run("String", func(t *testing.T) {
+		if result := NewFloat64(42.0).String(); result != "42" {
+			t.Errorf("String() returned an unexpected value. Expected %v, got %v", "42", result)
+		}
+		if result := NewFloat64(42.5).String(); result != "42.5" {
+			t.Errorf("String() returned an unexpected value. Expected %v, got %v", "42.5", result)
+		}
+	})
+}
+
+// TestUberCompatFloat32 follows go.uber.org/atomic's TestFloat32.
+func TestUberCompatFloat32(t *testing.T) {
+	atom := NewFloat32(4.2)
+
+	if result := atom.Load(); result != 4.2 {
+		t.Errorf("Load didn't work. Expected %v, got %v", 4.2, result)
+	}
+
+	if !atom.CAS(4.2, 0.5) {
+		t.Errorf("CAS didn't report a swap.")
+	}
+	if result := atom.Load(); result != 0.5 {
+		t.Errorf("CAS didn't set the correct value. Expected %v, got %v", 0.5, result)
+	}
+	if atom.CAS(0.0, 1.5) {
+		t.Errorf("CAS reported a swap.")
+	}
+
+	atom.Store(42.0)
+	if result := atom.Load(); result != 42.0 {
+		t.Errorf("Store didn't set the correct value. Expected %v, got %v", 42.0, result)
+	}
+	if result := atom.Add(0.5); result != 42.5 {
+		t.Errorf("Add didn't work. Expected %v, got %v", 42.5, result)
+	}
+	if result := atom.Sub(0.5); result != 42.0 {
+		t.Errorf("Sub didn't work. Expected %v, got %v", 42.0, result)
+	}
+
+	if result := atom.Swap(45.0); result != 42.0 {
+		t.Errorf("Swap didn't return the old value. Expected %v, got %v", 42.0, result)
+	}
+	if result := atom.Load(); result != 45.0 {
+		t.Errorf("Swap didn't set the correct value. Expected %v, got %v", 45.0, result)
+	}
+
+	t.Run("JSON/Marshal", func(t *testing.T) {
+		atom.Store(42.5)
+		bytes, err := json.Marshal(atom)
+		if err != nil {
+			t.Fatalf("json.Marshal errored unexpectedly: %v", err)
+		}
+		if string(bytes) != "42.5" {
+			t.Errorf("json.Marshal encoded the wrong bytes. Expected %s, got %s", "42.5", bytes)
+		}
+	})
+
+	t.Run("JSON/Unmarshal", func(t *testing.T) {
+		if err := json.Unmarshal([]byte("40.5"), &atom); err != nil {
+			t.Fatalf("json.Unmarshal errored unexpectedly: %v", err)
+		}
+		if result := atom.Load(); result != 40.5 {
+			t.Errorf("json.Unmarshal didn't set the correct value. Expected %v, got %v", 40.5, result)
+		}
+	})
+
+	t.Run("JSON/Unmarshal/Error", func(t *testing.T) {
+		err := json.Unmarshal([]byte(`"40.5"`), &atom)
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			t.Errorf("json.Unmarshal failed with unexpected error %v, want UnmarshalTypeError.", err)
+		}
+	})
+
+	t.Run("String", func(t *testing.T) {
+		if result := NewFloat32(42.0).String(); result != "42" {
+			t.Errorf("String() returned an unexpected value. Expected %v, got %v", "42", result)
+		}
+		if result := NewFloat32(42.5).String(); result != "42.5" {
+			t.Errorf("String() returned an unexpected value. Expected %v, got %v", "42.5", result)
+		}
+	})
+}
+
+// TestUberCompatDifferences pins down how NaN, infinities and null are
+// handled, where this package deliberately differs from go.uber.org/atomic.
+// The README lists the same differences.
+func TestUberCompatDifferences(t *testing.T) {
+	same := func(a, b float64) bool { return a == b || math.IsNaN(a) && math.IsNaN(b) }
+	for _, tc := range []struct {
+		v    float64
+		want string
+	}{
+		{math.NaN(), `"NaN"`},
+		{math.Inf(1), `"+Inf"`},
+		{math.Inf(-1), `"-Inf"`},
+	} {
+		atom := NewFloat64(tc.v)
+		atom32 := NewFloat32(float32(tc.v))
+
+		// go.uber.org/atomic: NaN, +Inf and -Inf, unquoted.
+		if result := atom.String(); result != tc.want {
+			t.Errorf("String() of %v: expected %v, got %v", tc.v, tc.want, result)
+		}
+		if result := atom32.String(); result != tc.want {
+			t.Errorf("Float32 String() of %v: expected %v, got %v", tc.v, tc.want, result)
+		}
+
+		// go.uber.org/atomic: an error.
+		if bytes, err := json.Marshal(atom); err != nil || string(bytes) != tc.want {
+			t.Errorf("json.Marshal of %v: expected %v, got %s, %v", tc.v, tc.want, bytes, err)
+		}
+		if bytes, err := json.Marshal(atom32); err != nil || string(bytes) != tc.want {
+			t.Errorf("Float32 json.Marshal of %v: expected %v, got %s, %v", tc.v, tc.want, bytes, err)
+		}
+
+		// go.uber.org/atomic: an *json.UnmarshalTypeError.
+		var back Float64
+		var back32 Float32
+		if err := json.Unmarshal([]byte(tc.want), &back); err != nil || !same(back.Load(), tc.v) {
+			t.Errorf("json.Unmarshal of %v: expected %v, got %v, %v", tc.want, tc.v, back.Load(), err)
+		}
+		if err := json.Unmarshal([]byte(tc.want), &back32); err != nil || !same(float64(back32.Load()), tc.v) {
+			t.Errorf("Float32 json.Unmarshal of %v: expected %v, got %v, %v", tc.want, tc.v, back32.Load(), err)
+		}
+	}
+
+	// go.uber.org/atomic: stores 0.
+	atom := NewFloat64(42.5)
+	if err := json.Unmarshal([]byte("null"), atom); err != nil || atom.Load() != 42.5 {
+		t.Errorf("json.Unmarshal of null: expected %v unchanged, got %v, %v", 42.5, atom.Load(), err)
+	}
+	atom32 := NewFloat32(42.5)
+	if err := json.Unmarshal([]byte("null"), atom32); err != nil || atom32.Load() != 42.5 {
+		t.Errorf("Float32 json.Unmarshal of null: expected %v unchanged, got %v, %v", 42.5, atom32.Load(), err)
+	}
+}
+
+// TestUberCompatStress follows go.uber.org/atomic's stress test for floats:
+// every method runs concurrently without a data race.
+func TestUberCompatStress(t *testing.T) {
+	const itemsCount = 1000
+	const gorotines = 10
+	var f64 Float64
+	var f32 Float32
+
+	done := make(chan bool)
+	for i := 0; i < gorotines; i++ {
+		go func() {
+			for j := 0; j < itemsCount; j++ {
+				f64.Load()
+				f64.Store(1)
+				f64.Add(1)
+				f64.Sub(2)
+				f64.CAS(1, 0)
+				f64.Swap(5)
+				f32.Load()
+				f32.Store(1)
+				f32.Add(1)
+				f32.Sub(2)
+				f32.CAS(1, 0)
+				f32.Swap(5)
+			}
+			done <- true
+		}()
+	}
+	for i := 0; i < gorotines; i++ {
+		<-done
+	}
+}
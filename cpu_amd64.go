@@ -0,0 +1,26 @@
+package atomic_float
+
+// See golang.org/x/sys/cpu/cpu_x86.go
+
+// x86HasFMA reports whether the CPU supports the FMA3 instructions and the
+// OS saves the AVX register state, so VFMADD231SD/VFMADD231SS may be used.
+var x86HasFMA = detectFMA()
+
+func detectFMA() bool {
+	const (
+		cpuidFMA     = 1 << 12
+		cpuidOSXSAVE = 1 << 27
+		cpuidAVX     = 1 << 28
+	)
+	_, _, ecx, _ := cpuid(1, 0)
+	if ecx&cpuidOSXSAVE == 0 || ecx&cpuidAVX == 0 || ecx&cpuidFMA == 0 {
+		return false
+	}
+	// XMM and YMM state must be enabled by the OS for VEX encoded instructions.
+	eax, _ := xgetbv()
+	return eax&(1<<1) != 0 && eax&(1<<2) != 0
+}
+
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+func xgetbv() (eax, edx uint32)
@@ -0,0 +1,240 @@
+package atomic_float
+
+import (
+	"math"
+	"math/big"
+	"runtime"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Every finite float64 is an integer multiple of 2^-1074, the smallest
+// subnormal, and less than 2^1024, so it is an integer N times 2^-1074 with N
+// below 2^2098. ExactSum keeps the sum of such N in limbs of 32 bits held in
+// int64s, leaving room for carries; a limb that grows past 2^62 moves its high
+// part into the next limb.
+const (
+	exactSumLimbBits = 32
+	exactSumLimbs    = 2098/exactSumLimbBits + 1 + 2
+	exactSumCarryAt  = 1 << 62
+	// exactSumSpins is how many times Sum spins for a snapshot before it
+	// holds new Adds back and yields to the ones in flight.
+	exactSumSpins = 100
+	// exactSumShards is the number of cache lines the counters of Adds in
+	// flight are spread over.
+	exactSumShardBits = 3
+	exactSumShards    = 1 << exactSumShardBits
+)
+
+// exactSumShard counts the Adds that began and completed on one shard. It
+// fills a cache line, so Adds on different shards do not contend for it.
+type exactSumShard struct {
+	started, finished atomic.Uint64
+	_                 [cacheLineSize - 2*unsafe.Sizeof(atomic.Uint64{})]byte
+}
+
+// ExactSum is a concurrent accumulator whose sum is exact: Add never rounds,
+// and Sum rounds the exact total once, to the nearest float64. The result is
+// therefore bit-identical for the same multiset of inputs in any order and
+// any interleaving of goroutines, unlike repeated AddFloat64. The zero value
+// is an empty sum. An ExactSum must not be copied.
+type ExactSum struct {
+	limbs [exactSumLimbs]atomic.Int64
+
+	nan, posInf, negInf atomic.Int64
+	// negZero and nonNegZero record Adds of -0 and of anything else, so that
+	// a sum of only -0 values is -0 as in IEEE 754 arithmetic.
+	negZero, nonNegZero atomic.Bool
+
+	// An Add writes up to three limbs plus carries. The shards count Adds
+	// that began and completed, so Sum can tell that none is in flight;
+	// gate is closed by a Sum that keeps finding one. gate is only read by
+	// Adds and sits on a cache line of its own.
+	_      [cacheLineSize]byte
+	gate   atomic.Int32
+	_      [cacheLineSize - unsafe.Sizeof(atomic.Int32{})]byte
+	shards [exactSumShards]exactSumShard
+}
+
+// Add adds x to the sum. It takes no lock, and Adds in different goroutines
+// share only the limbs they touch and one of several counters. Add is not
+// lock-free, though: a Sum that keeps finding Adds in flight holds new Adds
+// back until it has its snapshot.
+func (s *ExactSum) Add(x float64) {
+	for s.gate.Load() != 0 {
+		runtime.Gosched()
+	}
+	sh := s.shard()
+	sh.started.Add(1)
+	s.add(x)
+	sh.finished.Add(1)
+}
+
+// shard returns the shard for the calling goroutine, chosen from the address
+// of its stack, so that a goroutine keeps using one shard while its stack
+// stays in place and different goroutines spread over all of them.
+func (s *ExactSum) shard() *exactSumShard {
+	var b byte
+	h := uint64(uintptr(unsafe.Pointer(&b))>>11) * 0x9e3779b97f4a7c15
+	return &s.shards[h>>(64-exactSumShardBits)]
+}
+
+// finishedAdds returns the number of Adds completed on all shards.
+func (s *ExactSum) finishedAdds() uint64 {
+	var n uint64
+	for i := range s.shards {
+		n += s.shards[i].finished.Load()
+	}
+	return n
+}
+
+// startedAdds returns the number of Adds begun on all shards.
+func (s *ExactSum) startedAdds() uint64 {
+	var n uint64
+	for i := range s.shards {
+		n += s.shards[i].started.Load()
+	}
+	return n
+}
+
+func (s *ExactSum) add(x float64) {
+	switch {
+	case math.IsNaN(x):
+		s.nan.Add(1)
+		return
+	case math.IsInf(x, 1):
+		s.posInf.Add(1)
+		return
+	case math.IsInf(x, -1):
+		s.negInf.Add(1)
+		return
+	}
+	b := math.Float64bits(x)
+	if b == 1<<63 {
+		if !s.negZero.Load() {
+			s.negZero.Store(true)
+		}
+		return
+	}
+	if !s.nonNegZero.Load() {
+		s.nonNegZero.Store(true)
+	}
+	exp := int(b>>52) & 0x7ff
+	mant := b & (1<<52 - 1)
+	if mant == 0 && exp == 0 {
+		return
+	}
+	// x = mant * 2^(exp-1075) for normal numbers, whose implicit bit is
+	// set, and mant * 2^-1074 for subnormals, so N = mant << (exp-1).
+	if exp == 0 {
+		exp = 1
+	} else {
+		mant |= 1 << 52
+	}
+	pos := exp - 1
+	k, shift := pos/exactSumLimbBits, uint(pos%exactSumLimbBits)
+	hi, lo := mant>>(64-shift), mant<<shift
+	chunks := [3]int64{int64(lo & (1<<32 - 1)), int64(lo >> 32), int64(hi)}
+	for i, c := range chunks {
+		if c == 0 {
+			continue
+		}
+		if b>>63 != 0 {
+			c = -c
+		}
+		s.addLimb(k+i, c)
+	}
+}
+
+// addLimb adds c to limb i and carries into the following limbs as needed.
+// A carry subtracts from one limb what it adds to the next, so the total is
+// preserved whatever other Adds run in between.
+func (s *ExactSum) addLimb(i int, c int64) {
+	for {
+		v := s.limbs[i].Add(c)
+		if (v < exactSumCarryAt && v > -exactSumCarryAt) || i == len(s.limbs)-1 {
+			return
+		}
+		c = v >> exactSumLimbBits
+		s.limbs[i].Add(-c << exactSumLimbBits)
+		i++
+	}
+}
+
+// Sum returns the sum of the values added, correctly rounded to the nearest
+// float64, ties to even. It is ±Inf if the exact sum is out of range, NaN if
+// a NaN or both infinities were added, and the matching infinity if one of
+// them was. Sum is exact for the Adds that completed before it took its
+// snapshot and never includes part of an Add: it waits until no Add is in
+// progress, and if Adds keep running it holds new ones back until it has
+// its snapshot.
+func (s *ExactSum) Sum() float64 {
+	var (
+		limbs               [exactSumLimbs]int64
+		nan, posInf, negInf int64
+		negZero, nonNegZero bool
+	)
+	for try := 0; ; try++ {
+		if try == exactSumSpins {
+			s.gate.Add(1)
+			defer s.gate.Add(-1)
+		}
+		// finished never exceeds started on any shard, so if the finished
+		// counters, all read first, add up to the started ones, every shard
+		// had no Add in flight between the two reads, in particular at the
+		// moment between reading the last finished and first started.
+		done := s.finishedAdds()
+		if s.startedAdds() != done {
+			if try >= exactSumSpins {
+				runtime.Gosched()
+			}
+			continue
+		}
+		for i := range limbs {
+			limbs[i] = s.limbs[i].Load()
+		}
+		nan, posInf, negInf = s.nan.Load(), s.posInf.Load(), s.negInf.Load()
+		negZero, nonNegZero = s.negZero.Load(), s.nonNegZero.Load()
+		if s.startedAdds() == done {
+			break
+		}
+	}
+
+	switch {
+	case nan > 0 || posInf > 0 && negInf > 0:
+		return math.NaN()
+	case posInf > 0:
+		return math.Inf(1)
+	case negInf > 0:
+		return math.Inf(-1)
+	}
+
+	n := new(big.Int)
+	limb := new(big.Int)
+	for i := len(limbs) - 1; i >= 0; i-- {
+		n.Lsh(n, exactSumLimbBits)
+		n.Add(n, limb.SetInt64(limbs[i]))
+	}
+	if n.Sign() == 0 {
+		if negZero && !nonNegZero {
+			return math.Copysign(0, -1)
+		}
+		return 0
+	}
+	f := new(big.Float).SetInt(n)
+	f.SetMantExp(f, -1074)
+	sum, _ := f.Float64()
+	return sum
+}
+
+// Reset sets the sum back to zero. It must not run concurrently with Add.
+func (s *ExactSum) Reset() {
+	for i := range s.limbs {
+		s.limbs[i].Store(0)
+	}
+	s.nan.Store(0)
+	s.posInf.Store(0)
+	s.negInf.Store(0)
+	s.negZero.Store(false)
+	s.nonNegZero.Store(false)
+}
@@ -0,0 +1,274 @@
+package atomic_float
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"unsafe"
+)
+
+// ratSum returns the exact sum of finite values rounded to the nearest
+// float64, computed independently of ExactSum with big.Rat.
+func ratSum(vals []float64) float64 {
+	sum := new(big.Rat)
+	x := new(big.Rat)
+	for _, v := range vals {
+		sum.Add(sum, x.SetFloat64(v))
+	}
+	f, _ := sum.Float64()
+	return f
+}
+
+// cancellingValues returns values over the whole exponent range, each with a
+// slightly perturbed negation, so the exact sum is small and any rounding in
+// between would show.
+func cancellingValues(r *rand.Rand, n int) []float64 {
+	vals := make([]float64, 0, n)
+	for len(vals) < n {
+		v := math.Ldexp(r.Float64(), r.Intn(2098)-1074)
+		vals = append(vals, v, -math.Nextafter(v, 0))
+	}
+	return vals
+}
+
+func TestExactSum(t *testing.T) {
+	var tenth [10]float64
+	for i := range tenth {
+		tenth[i] = 0.1
+	}
+	tests := []struct {
+		name string
+		vals []float64
+		want float64
+	}{
+		{"empty", nil, 0},
+		{"tenths", tenth[:], 1},
+		{"cancellation", []float64{1, 1e-16, -1}, 1e-16},
+		{"large intermediate", []float64{math.MaxFloat64, math.MaxFloat64, -math.MaxFloat64}, math.MaxFloat64},
+		{"overflow", []float64{math.MaxFloat64, math.MaxFloat64}, math.Inf(1)},
+		{"subnormals", []float64{math.SmallestNonzeroFloat64, math.SmallestNonzeroFloat64, math.SmallestNonzeroFloat64}, 3 * math.SmallestNonzeroFloat64},
+		{"halfway to even", []float64{1, math.Ldexp(1, -53)}, 1},
+		{"just above halfway", []float64{1, math.Ldexp(1, -53), math.Ldexp(1, -1074)}, math.Nextafter(1, 2)},
+		{"negative", []float64{-2.5, -0.25, 1}, -1.75},
+		{"exact zero", []float64{3.5, -3.5}, 0},
+	}
+	for _, tt := range tests {
+		var s ExactSum
+		for _, v := range tt.vals {
+			s.Add(v)
+		}
+		if result := s.Sum(); math.Float64bits(result) != math.Float64bits(tt.want) {
+			t.Errorf("%s: Expected %v, got %v", tt.name, tt.want, result)
+		}
+	}
+}
+
+func TestExactSumSpecial(t *testing.T) {
+	negZero := math.Copysign(0, -1)
+	tests := []struct {
+		name string
+		vals []float64
+		want float64
+	}{
+		{"NaN", []float64{1, math.NaN()}, math.NaN()},
+		{"+Inf", []float64{1, math.Inf(1), -math.MaxFloat64}, math.Inf(1)},
+		{"-Inf", []float64{math.Inf(-1), math.MaxFloat64}, math.Inf(-1)},
+		{"Inf-Inf", []float64{math.Inf(1), math.Inf(-1)}, math.NaN()},
+		{"-0", []float64{negZero, negZero}, negZero},
+		{"-0+0", []float64{negZero, 0}, 0},
+		{"1-1-0", []float64{1, -1, negZero}, 0},
+	}
+	for _, tt := range tests {
+		var s ExactSum
+		for _, v := range tt.vals {
+			s.Add(v)
+		}
+		result := s.Sum()
+		if math.IsNaN(tt.want) {
+			if !math.IsNaN(result) {
+				t.Errorf("%s: Expected NaN, got %v", tt.name, result)
+			}
+		} else if math.Float64bits(result) != math.Float64bits(tt.want) {
+			t.Errorf("%s: Expected %v, got %v", tt.name, tt.want, result)
+		}
+	}
+
+	var s ExactSum
+	s.Add(math.NaN())
+	s.Add(1)
+	s.Reset()
+	s.Add(2)
+	if result := s.Sum(); result != 2 {
+		t.Errorf("Expected %v after Reset, got %v", 2, result)
+	}
+}
+
+func TestExactSumMatchesRat(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for round := 0; round < 20; round++ {
+		vals := cancellingValues(r, 200)
+		for i := 0; i < 20; i++ {
+			vals = append(vals, (r.Float64()-0.5)*math.Ldexp(1, r.Intn(200)-100))
+		}
+		var s ExactSum
+		for _, v := range vals {
+			s.Add(v)
+		}
+		if want, result := ratSum(vals), s.Sum(); math.Float64bits(result) != math.Float64bits(want) {
+			t.Errorf("Round %d: expected %v, got %v", round, want, result)
+		}
+	}
+}
+
+// TestExactSumCarry starts a limb just below the carry threshold so that an
+// Add has to normalize it.
+func TestExactSumCarry(t *testing.T) {
+	var s ExactSum
+	s.limbs[1].Store(exactSumCarryAt - 1)
+	// 2^-1042 is N = 2^32, one unit of limb 1.
+	s.Add(math.Ldexp(1, -1042))
+	if result := s.limbs[1].Load(); result >= exactSumCarryAt || result < 0 {
+		t.Errorf("Expected limb 1 to be normalized, got %#x", result)
+	}
+	want := math.Ldexp(float64(exactSumCarryAt), -1042)
+	if result := s.Sum(); result != want {
+		t.Errorf("Expected %v, got %v", want, result)
+	}
+
+	s.Reset()
+	s.limbs[1].Store(-exactSumCarryAt + 1)
+	s.Add(-math.Ldexp(1, -1042))
+	if result := s.Sum(); result != -want {
+		t.Errorf("Expected %v, got %v", -want, result)
+	}
+}
+
+// TestExactSumShuffled adds the same values in a different order, split
+// across goroutines differently, every round and checks that the sum is
+// bit-identical every time.
+func TestExactSumShuffled(t *testing.T) {
+	const goroutines = 8
+	const rounds = 20
+	r := rand.New(rand.NewSource(2))
+	vals := cancellingValues(r, 4000)
+	for i := 0; i < 1000; i++ {
+		vals = append(vals, r.NormFloat64()*1e6, r.NormFloat64()*1e-6)
+	}
+	want := ratSum(vals)
+
+	for round := 0; round < rounds; round++ {
+		r.Shuffle(len(vals), func(i, j int) { vals[i], vals[j] = vals[j], vals[i] })
+		var s ExactSum
+		done := make(chan bool)
+		for i := 0; i < goroutines; i++ {
+			lo, hi := i*len(vals)/goroutines, (i+1)*len(vals)/goroutines
+			go func() {
+				for _, v := range vals[lo:hi] {
+					s.Add(v)
+				}
+				done <- true
+			}()
+		}
+		for i := 0; i < goroutines; i++ {
+			<-done
+		}
+		if result := s.Sum(); math.Float64bits(result) != math.Float64bits(want) {
+			t.Errorf("Round %d: expected %v, got %v", round, want, result)
+		}
+	}
+}
+
+// TestExactSumConcurrentSum calls Sum while every writer keeps adding and
+// removing its own value, each spanning three limbs. Every snapshot must be
+// the base plus the values of some subset of the writers; a Sum that saw part
+// of an Add would not be. The base puts one limb just below the carry
+// threshold so that some of the Adds carry.
+func TestExactSumConcurrentSum(t *testing.T) {
+	vals := []float64{0x1.fffffffffffffp+10, 0x1.123456789abcdp+10, -0x1.5555555555555p+11, 0x1.3333333333333p+9}
+	// All four values start in limb 32, so their middle chunks go to limb 33.
+	base := math.Ldexp(exactSumCarryAt-1<<33, 33*exactSumLimbBits-1074)
+
+	want := make(map[uint64]bool)
+	for mask := 0; mask < 1<<len(vals); mask++ {
+		subset := []float64{base}
+		for i, v := range vals {
+			if mask&(1<<i) != 0 {
+				subset = append(subset, v)
+			}
+		}
+		want[math.Float64bits(ratSum(subset))] = true
+	}
+
+	var s ExactSum
+	s.limbs[33].Store(exactSumCarryAt - 1<<33)
+	var stop atomic.Bool
+	done := make(chan bool)
+	for _, v := range vals {
+		go func(v float64) {
+			for !stop.Load() {
+				s.Add(v)
+				s.Add(-v)
+			}
+			done <- true
+		}(v)
+	}
+	for i := 0; i < 20000; i++ {
+		if result := s.Sum(); !want[math.Float64bits(result)] {
+			t.Errorf("Sum %d: got %v, which is not the sum of any subset of the Adds", i, result)
+			break
+		}
+	}
+	stop.Store(true)
+	for range vals {
+		<-done
+	}
+	if result := s.Sum(); result != base {
+		t.Errorf("Expected %v, got %v", base, result)
+	}
+}
+
+func TestExactSumShards(t *testing.T) {
+	var s ExactSum
+	if size := unsafe.Sizeof(s.shards[0]); size != cacheLineSize {
+		t.Errorf("Expected shard size %v, got %v", cacheLineSize, size)
+	}
+	gate := uintptr(unsafe.Pointer(&s.gate))
+	if lo, hi := uintptr(unsafe.Pointer(&s.limbs[len(s.limbs)-1])), uintptr(unsafe.Pointer(&s.shards[0])); gate-lo < cacheLineSize || hi-gate < cacheLineSize {
+		t.Errorf("Expected gate on a cache line of its own")
+	}
+
+	used := make(chan *exactSumShard)
+	for i := 0; i < 64; i++ {
+		go func() {
+			used <- s.shard()
+		}()
+	}
+	shards := make(map[*exactSumShard]bool)
+	for i := 0; i < 64; i++ {
+		shards[<-used] = true
+	}
+	if len(shards) < 2 {
+		t.Errorf("Expected 64 goroutines to use several shards, got %v", len(shards))
+	}
+
+	done := make(chan bool)
+	for i := 0; i < 8; i++ {
+		go func() {
+			for j := 0; j < 1000; j++ {
+				s.Add(1)
+			}
+			done <- true
+		}()
+	}
+	for i := 0; i < 8; i++ {
+		<-done
+	}
+	if started, finished := s.startedAdds(), s.finishedAdds(); started != 8000 || finished != 8000 {
+		t.Errorf("Expected 8000 started and finished Adds, got %v and %v", started, finished)
+	}
+	if result := s.Sum(); result != 8000 {
+		t.Errorf("Expected %v, got %v", 8000, result)
+	}
+}
@@ -0,0 +1,28 @@
+// Package expvarfloat publishes atomic floats from the parent package with
+// expvar. It is separate from the parent package because importing expvar
+// links in net/http and registers the /debug/vars handler on
+// http.DefaultServeMux; the Float32 and Float64 types already satisfy
+// expvar.Var through their String methods.
+package expvarfloat
+
+import (
+	"expvar"
+
+	atomic_float "atomic-float"
+)
+
+// PublishFloat32 creates a new Float32 and publishes it with expvar under
+// name. Like expvar.NewFloat, it panics if name is already registered.
+func PublishFloat32(name string) *atomic_float.Float32 {
+	x := new(atomic_float.Float32)
+	expvar.Publish(name, x)
+	return x
+}
+
+// PublishFloat64 creates a new Float64 and publishes it with expvar under
+// name. Like expvar.NewFloat, it panics if name is already registered.
+func PublishFloat64(name string) *atomic_float.Float64 {
+	x := new(atomic_float.Float64)
+	expvar.Publish(name, x)
+	return x
+}
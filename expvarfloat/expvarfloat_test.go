@@ -0,0 +1,44 @@
+package expvarfloat
+
+import (
+	"encoding/json"
+	"expvar"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPublishDebugVars(t *testing.T) {
+	f64 := PublishFloat64("atomic_float_test_f64")
+	f32 := PublishFloat32("atomic_float_test_f32")
+	nan := PublishFloat64("atomic_float_test_nan")
+	f64.Add(1.25)
+	f32.Add(-0.5)
+	nan.Store(math.NaN())
+
+	if v := expvar.Get("atomic_float_test_f64"); v != f64 {
+		t.Errorf("Expected published variable %p, got %v", f64, v)
+	}
+
+	srv := httptest.NewServer(expvar.Handler())
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/debug/vars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var vars map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&vars); err != nil {
+		t.Fatalf("Decode /debug/vars: %v", err)
+	}
+	if result := vars["atomic_float_test_f64"]; result != 1.25 {
+		t.Errorf("Expected %v, got %v", 1.25, result)
+	}
+	if result := vars["atomic_float_test_f32"]; result != -0.5 {
+		t.Errorf("Expected %v, got %v", -0.5, result)
+	}
+	if result := vars["atomic_float_test_nan"]; result != "NaN" {
+		t.Errorf("Expected %v, got %v", "NaN", result)
+	}
+}
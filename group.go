@@ -0,0 +1,64 @@
+package atomic_float
+
+// FloatGroup is a fixed number of float64 values that are updated together
+// and read as a consistent snapshot.
+//
+// Updates are serialized. Readers never block writers or each other: they
+// load the values atomically and only retry when an update overlapped the
+// read, so a snapshot never mixes values from different updates.
+//
+// A FloatGroup must not be copied.
+type FloatGroup struct {
+	lock    seqlock
+	vals    []float64
+	scratch []float64
+}
+
+// NewFloatGroup returns a FloatGroup of n values, all zero.
+func NewFloatGroup(n int) *FloatGroup {
+	return &FloatGroup{vals: make([]float64, n), scratch: make([]float64, n)}
+}
+
+// Len returns the number of values in g.
+func (g *FloatGroup) Len() int { return len(g.vals) }
+
+// Load atomically loads the i'th value.
+func (g *FloatGroup) Load(i int) float64 { return LoadFloat64(&g.vals[i]) }
+
+// Snapshot returns a copy of all values as of a single point in time.
+func (g *FloatGroup) Snapshot() []float64 {
+	dst := make([]float64, len(g.vals))
+	g.Read(dst)
+	return dst
+}
+
+// Read is like Snapshot but copies the values into dst, which must have
+// length g.Len().
+func (g *FloatGroup) Read(dst []float64) {
+	dst = dst[:len(g.vals)]
+	for {
+		s := g.lock.beginRead()
+		for i := range g.vals {
+			dst[i] = LoadFloat64(&g.vals[i])
+		}
+		if !g.lock.retry(s) {
+			return
+		}
+	}
+}
+
+// Update calls fn with the current values and atomically replaces them with
+// whatever fn leaves in the slice. fn runs while other updates wait, but
+// readers keep seeing the previous values until it returns. fn must not
+// retain vals or call Update.
+func (g *FloatGroup) Update(fn func(vals []float64)) {
+	g.lock.lock()
+	defer g.lock.unlock()
+	copy(g.scratch, g.vals)
+	fn(g.scratch)
+	g.lock.beginWrite()
+	for i, v := range g.scratch {
+		StoreFloat64(&g.vals[i], v)
+	}
+	g.lock.endWrite()
+}
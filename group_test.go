@@ -0,0 +1,88 @@
+package atomic_float
+
+import (
+	"testing"
+)
+
+func TestFloatGroup(t *testing.T) {
+	g := NewFloatGroup(3)
+	if result := g.Len(); result != 3 {
+		t.Errorf("Expected %v, got %v", 3, result)
+	}
+	g.Update(func(vals []float64) {
+		vals[0] = 1
+		vals[2] = -2.5
+	})
+	g.Update(func(vals []float64) {
+		vals[1] = vals[0] + vals[2]
+	})
+	want := []float64{1, -1.5, -2.5}
+	for i, v := range g.Snapshot() {
+		if v != want[i] {
+			t.Errorf("Value %d: expected %v, got %v", i, want[i], v)
+		}
+		if result := g.Load(i); result != want[i] {
+			t.Errorf("Value %d: expected %v, got %v", i, want[i], result)
+		}
+	}
+}
+
+func TestFloatGroupUpdatePanic(t *testing.T) {
+	g := NewFloatGroup(1)
+	func() {
+		defer func() { recover() }()
+		g.Update(func(vals []float64) {
+			vals[0] = 1
+			panic("abort")
+		})
+	}()
+	if result := g.Load(0); result != 0 {
+		t.Errorf("Expected aborted update to be discarded, got %v", result)
+	}
+	g.Update(func(vals []float64) { vals[0] = 2 })
+	if result := g.Load(0); result != 2 {
+		t.Errorf("Expected %v, got %v", 2, result)
+	}
+}
+
+// TestFloatGroupConsistent checks that readers never see a mix of values
+// from different updates.
+func TestFloatGroupConsistent(t *testing.T) {
+	const itemsCount = 5000
+	const gorotines = 4
+	g := NewFloatGroup(12)
+
+	stop := make(chan bool)
+	done := make(chan bool)
+	for i := 0; i < gorotines; i++ {
+		go func() {
+			snap := make([]float64, g.Len())
+			for {
+				select {
+				case <-stop:
+					done <- true
+					return
+				default:
+				}
+				g.Read(snap)
+				for _, v := range snap {
+					if v != snap[0] {
+						t.Errorf("Inconsistent snapshot %v", snap)
+						break
+					}
+				}
+			}
+		}()
+	}
+	for i := 1; i <= itemsCount; i++ {
+		g.Update(func(vals []float64) {
+			for j := range vals {
+				vals[j] = float64(i)
+			}
+		})
+	}
+	close(stop)
+	for i := 0; i < gorotines; i++ {
+		<-done
+	}
+}
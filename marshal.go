@@ -0,0 +1,251 @@
+package atomic_float
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// The marshaling methods have pointer receivers because Float32 and Float64
+// must not be copied: a value receiver would read the value with a plain,
+// non-atomic copy. encoding/json and encoding/gob only find them on
+// addressable values, so marshal a pointer to the struct holding the field.
+// Marshaling the struct itself, or a map holding it, silently encodes each
+// Float32 and Float64 as {}.
+
+// NonFiniteEncoding selects how MarshalJSON encodes NaN and infinities, which
+// have no JSON number representation.
+type NonFiniteEncoding int
+
+const (
+	// NonFiniteString encodes NaN, +Inf and -Inf as the JSON strings "NaN",
+	// "+Inf" and "-Inf", the same way String does.
+	NonFiniteString NonFiniteEncoding = iota
+	// NonFiniteNull encodes NaN and infinities as null.
+	NonFiniteNull
+	// NonFiniteError makes MarshalJSON fail, as encoding/json does for a
+	// plain float64.
+	NonFiniteError
+)
+
+var errBinaryLength = errors.New("atomic_float: UnmarshalBinary: wrong data length")
+
+func appendJSON(b []byte, f float64, bitSize int, enc NonFiniteEncoding) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		switch enc {
+		case NonFiniteNull:
+			return append(b, "null"...), nil
+		case NonFiniteError:
+			return b, fmt.Errorf("atomic_float: MarshalJSON: unsupported value %v", f)
+		}
+	}
+	return append(b, formatJSON(f, bitSize)...), nil
+}
+
+// unmarshalJSON decodes a JSON number or one of the strings written by
+// NonFiniteString. It reports false for null, which leaves the value as is.
+func unmarshalJSON(data []byte, bitSize int) (f float64, ok bool, err error) {
+	if string(data) == "null" {
+		return 0, false, nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		switch string(data) {
+		case `"NaN"`:
+			return math.NaN(), true, nil
+		case `"+Inf"`, `"Inf"`:
+			return math.Inf(1), true, nil
+		case `"-Inf"`:
+			return math.Inf(-1), true, nil
+		}
+		// The same error encoding/json returns for a plain float64.
+		typ := reflect.TypeOf(float64(0))
+		if bitSize == 32 {
+			typ = reflect.TypeOf(float32(0))
+		}
+		return 0, false, &json.UnmarshalTypeError{Value: "string " + string(data), Type: typ}
+	}
+	if bitSize == 32 {
+		var v float32
+		err = json.Unmarshal(data, &v)
+		f = float64(v)
+	} else {
+		err = json.Unmarshal(data, &f)
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return f, true, nil
+}
+
+// MarshalJSON encodes x as a JSON number. NaN and infinities are encoded as
+// with NonFiniteString; go.uber.org/atomic returns an error for them, like
+// NonFiniteError. Use JSONFloat32 to choose the encoding for a value.
+func (x *Float32) MarshalJSON() ([]byte, error) {
+	return appendJSON(nil, float64(x.Load()), 32, NonFiniteString)
+}
+
+// AppendJSON appends the JSON encoding of x to b, encoding NaN and infinities
+// according to enc. On error b is returned unchanged.
+func (x *Float32) AppendJSON(b []byte, enc NonFiniteEncoding) ([]byte, error) {
+	return appendJSON(b, float64(x.Load()), 32, enc)
+}
+
+// UnmarshalJSON stores a JSON number, or one of the strings "NaN", "+Inf" and
+// "-Inf", into x. null leaves x unchanged, as for a plain float32; note that
+// go.uber.org/atomic stores 0 for null and rejects the strings.
+func (x *Float32) UnmarshalJSON(data []byte) error {
+	f, ok, err := unmarshalJSON(data, 32)
+	if ok {
+		x.Store(float32(f))
+	}
+	return err
+}
+
+// MarshalText encodes x in the format of strconv.FormatFloat with 'g'.
+func (x *Float32) MarshalText() ([]byte, error) {
+	return strconv.AppendFloat(nil, float64(x.Load()), 'g', -1, 32), nil
+}
+
+// UnmarshalText stores a number parsed with strconv.ParseFloat into x.
+func (x *Float32) UnmarshalText(text []byte) error {
+	f, err := strconv.ParseFloat(string(text), 32)
+	if err != nil {
+		return err
+	}
+	x.Store(float32(f))
+	return nil
+}
+
+// MarshalBinary encodes the bit pattern of x in 4 big-endian bytes.
+func (x *Float32) MarshalBinary() ([]byte, error) {
+	return binary.BigEndian.AppendUint32(nil, math.Float32bits(x.Load())), nil
+}
+
+// UnmarshalBinary stores the bit pattern encoded by MarshalBinary into x.
+func (x *Float32) UnmarshalBinary(data []byte) error {
+	if len(data) != 4 {
+		return errBinaryLength
+	}
+	x.Store(math.Float32frombits(binary.BigEndian.Uint32(data)))
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder using MarshalBinary.
+func (x *Float32) GobEncode() ([]byte, error) { return x.MarshalBinary() }
+
+// GobDecode implements gob.GobDecoder using UnmarshalBinary.
+func (x *Float32) GobDecode(data []byte) error { return x.UnmarshalBinary(data) }
+
+// MarshalJSON encodes x as a JSON number. NaN and infinities are encoded as
+// with NonFiniteString; go.uber.org/atomic returns an error for them, like
+// NonFiniteError. Use JSONFloat64 to choose the encoding for a value.
+func (x *Float64) MarshalJSON() ([]byte, error) {
+	return appendJSON(nil, x.Load(), 64, NonFiniteString)
+}
+
+// AppendJSON appends the JSON encoding of x to b, encoding NaN and infinities
+// according to enc. On error b is returned unchanged.
+func (x *Float64) AppendJSON(b []byte, enc NonFiniteEncoding) ([]byte, error) {
+	return appendJSON(b, x.Load(), 64, enc)
+}
+
+// UnmarshalJSON stores a JSON number, or one of the strings "NaN", "+Inf" and
+// "-Inf", into x. null leaves x unchanged, as for a plain float64; note that
+// go.uber.org/atomic stores 0 for null and rejects the strings.
+func (x *Float64) UnmarshalJSON(data []byte) error {
+	f, ok, err := unmarshalJSON(data, 64)
+	if ok {
+		x.Store(f)
+	}
+	return err
+}
+
+// MarshalText encodes x in the format of strconv.FormatFloat with 'g'.
+func (x *Float64) MarshalText() ([]byte, error) {
+	return strconv.AppendFloat(nil, x.Load(), 'g', -1, 64), nil
+}
+
+// UnmarshalText stores a number parsed with strconv.ParseFloat into x.
+func (x *Float64) UnmarshalText(text []byte) error {
+	f, err := strconv.ParseFloat(string(text), 64)
+	if err != nil {
+		return err
+	}
+	x.Store(f)
+	return nil
+}
+
+// MarshalBinary encodes the bit pattern of x in 8 big-endian bytes.
+func (x *Float64) MarshalBinary() ([]byte, error) {
+	return binary.BigEndian.AppendUint64(nil, math.Float64bits(x.Load())), nil
+}
+
+// UnmarshalBinary stores the bit pattern encoded by MarshalBinary into x.
+func (x *Float64) UnmarshalBinary(data []byte) error {
+	if len(data) != 8 {
+		return errBinaryLength
+	}
+	x.Store(math.Float64frombits(binary.BigEndian.Uint64(data)))
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder using MarshalBinary.
+func (x *Float64) GobEncode() ([]byte, error) { return x.MarshalBinary() }
+
+// GobDecode implements gob.GobDecoder using UnmarshalBinary.
+func (x *Float64) GobDecode(data []byte) error { return x.UnmarshalBinary(data) }
+
+// JSONFloat32 encodes the Float32 that F points to in JSON, encoding NaN and
+// infinities according to NonFinite, so that the encoding can be chosen per
+// value, e.g. per struct field:
+//
+//	type report struct {
+//		Ratio atomic_float.JSONFloat32
+//	}
+//
+//	json.Marshal(report{Ratio: atomic_float.JSONFloat32{F: &ratio, NonFinite: atomic_float.NonFiniteNull}})
+//
+// It only holds a pointer, so unlike Float32 it may be copied, and its
+// methods have value receivers that encoding/json finds on any value.
+type JSONFloat32 struct {
+	F         *Float32
+	NonFinite NonFiniteEncoding
+}
+
+// MarshalJSON encodes the Float32 that j.F points to like its AppendJSON
+// method with j.NonFinite.
+func (j JSONFloat32) MarshalJSON() ([]byte, error) {
+	return j.F.AppendJSON(nil, j.NonFinite)
+}
+
+// UnmarshalJSON stores into the Float32 that j.F points to like its
+// UnmarshalJSON method.
+func (j JSONFloat32) UnmarshalJSON(data []byte) error {
+	return j.F.UnmarshalJSON(data)
+}
+
+// JSONFloat64 encodes the Float64 that F points to in JSON, encoding NaN and
+// infinities according to NonFinite, so that the encoding can be chosen per
+// value, e.g. per struct field. It only holds a pointer, so unlike Float64 it
+// may be copied, and its methods have value receivers that encoding/json
+// finds on any value.
+type JSONFloat64 struct {
+	F         *Float64
+	NonFinite NonFiniteEncoding
+}
+
+// MarshalJSON encodes the Float64 that j.F points to like its AppendJSON
+// method with j.NonFinite.
+func (j JSONFloat64) MarshalJSON() ([]byte, error) {
+	return j.F.AppendJSON(nil, j.NonFinite)
+}
+
+// UnmarshalJSON stores into the Float64 that j.F points to like its
+// UnmarshalJSON method.
+func (j JSONFloat64) UnmarshalJSON(data []byte) error {
+	return j.F.UnmarshalJSON(data)
+}
@@ -0,0 +1,240 @@
+package atomic_float
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+type snapshot struct {
+	Name  string
+	Ratio Float32
+	Total Float64
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	var s snapshot
+	s.Name = "cpu"
+	s.Ratio.Store(0.25)
+	s.Total.Store(-1e300)
+
+	data, err := json.Marshal(&s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"Name":"cpu","Ratio":0.25,"Total":-1e+300}`; string(data) != want {
+		t.Errorf("Expected %v, got %v", want, string(data))
+	}
+
+	var r snapshot
+	if err := json.Unmarshal(data, &r); err != nil {
+		t.Fatal(err)
+	}
+	if result := r.Ratio.Load(); result != 0.25 {
+		t.Errorf("Expected %v, got %v", 0.25, result)
+	}
+	if result := r.Total.Load(); result != -1e300 {
+		t.Errorf("Expected %v, got %v", -1e300, result)
+	}
+
+	r.Total.Store(7)
+	if err := json.Unmarshal([]byte(`{"Total":null}`), &r); err != nil {
+		t.Fatal(err)
+	}
+	if result := r.Total.Load(); result != 7 {
+		t.Errorf("Expected null to leave %v, got %v", 7, result)
+	}
+}
+
+func TestAppendJSON(t *testing.T) {
+	var f Float64
+	var f32 Float32
+	f.Store(math.NaN())
+	f32.Store(float32(math.Inf(1)))
+	for _, tc := range []struct {
+		enc    NonFiniteEncoding
+		want   string
+		want32 string
+	}{
+		{NonFiniteString, `["NaN"`, `["+Inf"`},
+		{NonFiniteNull, `[null`, `[null`},
+	} {
+		if data, err := f.AppendJSON([]byte("["), tc.enc); err != nil || string(data) != tc.want {
+			t.Errorf("Expected %v, got %s, %v", tc.want, data, err)
+		}
+		if data, err := f32.AppendJSON([]byte("["), tc.enc); err != nil || string(data) != tc.want32 {
+			t.Errorf("Expected %v, got %s, %v", tc.want32, data, err)
+		}
+	}
+	if data, err := f.AppendJSON([]byte("["), NonFiniteError); err == nil || string(data) != "[" {
+		t.Errorf("Expected error and unchanged buffer, got %s, %v", data, err)
+	}
+	f.Store(-0.25)
+	if data, err := f.AppendJSON(nil, NonFiniteError); err != nil || string(data) != "-0.25" {
+		t.Errorf("Expected %v, got %s, %v", "-0.25", data, err)
+	}
+}
+
+func TestJSONNonFinite(t *testing.T) {
+	var f Float64
+	f.Store(math.Inf(-1))
+	if data, err := json.Marshal(&f); err != nil || string(data) != `"-Inf"` {
+		t.Errorf("Expected %v, got %s, %v", `"-Inf"`, data, err)
+	}
+	f.Store(1.5)
+	if data, err := json.Marshal(&f); err != nil || string(data) != "1.5" {
+		t.Errorf("Expected %v, got %s, %v", "1.5", data, err)
+	}
+
+	for _, tc := range []struct {
+		in   string
+		want float64
+	}{
+		{`"+Inf"`, math.Inf(1)},
+		{`"Inf"`, math.Inf(1)},
+		{`"-Inf"`, math.Inf(-1)},
+	} {
+		if err := json.Unmarshal([]byte(tc.in), &f); err != nil || f.Load() != tc.want {
+			t.Errorf("Expected %v, got %v, %v", tc.want, f.Load(), err)
+		}
+	}
+	if err := json.Unmarshal([]byte(`"NaN"`), &f); err != nil || !math.IsNaN(f.Load()) {
+		t.Errorf("Expected NaN, got %v, %v", f.Load(), err)
+	}
+}
+
+func TestJSONFloat(t *testing.T) {
+	var ratio Float32
+	var total, delta Float64
+	ratio.Store(float32(math.NaN()))
+	total.Store(math.Inf(1))
+	delta.Store(math.Inf(-1))
+	type report struct {
+		Ratio JSONFloat32
+		Total JSONFloat64
+		Delta JSONFloat64
+	}
+	r := report{
+		Ratio: JSONFloat32{F: &ratio, NonFinite: NonFiniteNull},
+		Total: JSONFloat64{F: &total, NonFinite: NonFiniteString},
+		Delta: JSONFloat64{F: &delta, NonFinite: NonFiniteNull},
+	}
+	// The wrappers have value receivers, so a struct value marshals too.
+	data, err := json.Marshal(r)
+	if want := `{"Ratio":null,"Total":"+Inf","Delta":null}`; err != nil || string(data) != want {
+		t.Errorf("Expected %v, got %s, %v", want, data, err)
+	}
+	r.Delta.NonFinite = NonFiniteError
+	if _, err := json.Marshal(r); err == nil {
+		t.Errorf("Expected error marshaling %v", delta.Load())
+	}
+
+	if err := json.Unmarshal([]byte(`{"Ratio":0.5,"Total":null,"Delta":2}`), &r); err != nil {
+		t.Fatal(err)
+	}
+	if ratio.Load() != 0.5 || total.Load() != math.Inf(1) || delta.Load() != 2 {
+		t.Errorf("Expected 0.5 +Inf 2, got %v %v %v", ratio.Load(), total.Load(), delta.Load())
+	}
+}
+
+// TestMarshalAddressable pins down that the marshaling methods of Float32 and
+// Float64 are only found on addressable values.
+func TestMarshalAddressable(t *testing.T) {
+	m := map[string]snapshot{"cpu": {Name: "cpu"}}
+	data, err := json.Marshal(m)
+	if want := `{"cpu":{"Name":"cpu","Ratio":{},"Total":{}}}`; err != nil || string(data) != want {
+		t.Errorf("Expected %v, got %s, %v", want, data, err)
+	}
+	s := &snapshot{Name: "cpu"}
+	data, err = json.Marshal(map[string]*snapshot{"cpu": s})
+	if want := `{"cpu":{"Name":"cpu","Ratio":0,"Total":0}}`; err != nil || string(data) != want {
+		t.Errorf("Expected %v, got %s, %v", want, data, err)
+	}
+}
+
+func TestJSONUnmarshalErrors(t *testing.T) {
+	var f64 Float64
+	var f32 Float32
+	for _, in := range []string{`"1.5"`, `"nan"`, `true`, `{}`} {
+		if err := json.Unmarshal([]byte(in), &f64); err == nil {
+			t.Errorf("Expected error unmarshaling %v", in)
+		}
+	}
+	if err := json.Unmarshal([]byte(`1e300`), &f32); err == nil {
+		t.Errorf("Expected overflow error for Float32")
+	}
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	for _, v := range []float64{0, -2.5, 1e-310, math.Inf(1), math.Inf(-1)} {
+		var f, r Float64
+		f.Store(v)
+		text, err := f.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := r.UnmarshalText(text); err != nil {
+			t.Fatal(err)
+		}
+		if result := r.Load(); result != v {
+			t.Errorf("Expected %v, got %v", v, result)
+		}
+	}
+	var f Float32
+	if err := f.UnmarshalText([]byte("NaN")); err != nil || !math.IsNaN(float64(f.Load())) {
+		t.Errorf("Expected NaN, got %v, %v", f.Load(), err)
+	}
+	if err := f.UnmarshalText([]byte("seven")); err == nil {
+		t.Errorf("Expected error parsing invalid text")
+	}
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	nan := math.Float64frombits(0x7ff8_0000_dead_beef)
+	var f, r Float64
+	f.Store(nan)
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if result := math.Float64bits(r.Load()); result != 0x7ff8_0000_dead_beef {
+		t.Errorf("Expected bits %#x, got %#x", uint64(0x7ff8_0000_dead_beef), result)
+	}
+	if err := r.UnmarshalBinary(data[:4]); err == nil {
+		t.Errorf("Expected error for short data")
+	}
+
+	var f32, r32 Float32
+	f32.Store(-0.75)
+	data, _ = f32.MarshalBinary()
+	if len(data) != 4 {
+		t.Errorf("Expected 4 bytes, got %v", len(data))
+	}
+	if err := r32.UnmarshalBinary(data); err != nil || r32.Load() != -0.75 {
+		t.Errorf("Expected %v, got %v, %v", -0.75, r32.Load(), err)
+	}
+}
+
+func TestGobRoundTrip(t *testing.T) {
+	var s snapshot
+	s.Name = "mem"
+	s.Ratio.Store(float32(math.Inf(1)))
+	s.Total.Store(12.5)
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&s); err != nil {
+		t.Fatal(err)
+	}
+	var r snapshot
+	if err := gob.NewDecoder(&buf).Decode(&r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Name != "mem" || r.Ratio.Load() != float32(math.Inf(1)) || r.Total.Load() != 12.5 {
+		t.Errorf("Expected %v %v %v, got %v %v %v", "mem", math.Inf(1), 12.5, r.Name, r.Ratio.Load(), r.Total.Load())
+	}
+}
@@ -0,0 +1,76 @@
+// Package metrics provides Prometheus style gauges and counters backed by
+// atomic floats, and a registry that writes them in the Prometheus text
+// exposition format. It has no dependencies outside the standard library.
+package metrics
+
+import (
+	atomic_float "atomic-float"
+)
+
+// Gauge is a value that can go up and down. The zero value is a gauge set to
+// zero. A Gauge must not be copied.
+type Gauge struct {
+	v atomic_float.Float64
+}
+
+// Set sets the gauge to v.
+func (g *Gauge) Set(v float64) { g.v.Store(v) }
+
+// Add adds delta, which may be negative, to the gauge and returns the new value.
+func (g *Gauge) Add(delta float64) float64 { return g.v.Add(delta) }
+
+// Sub subtracts delta from the gauge and returns the new value.
+func (g *Gauge) Sub(delta float64) float64 { return g.v.Add(-delta) }
+
+// Inc increments the gauge by one.
+func (g *Gauge) Inc() { g.v.Add(1) }
+
+// Dec decrements the gauge by one.
+func (g *Gauge) Dec() { g.v.Add(-1) }
+
+// Value returns the current value of the gauge.
+func (g *Gauge) Value() float64 { return g.v.Load() }
+
+// Counter is a value that only goes up. The zero value is a counter at zero.
+// A Counter must not be copied.
+type Counter struct {
+	v atomic_float.Float64
+}
+
+// Inc increments the counter by one.
+func (c *Counter) Inc() { c.v.Add(1) }
+
+// Add adds delta to the counter and returns the new value. It panics if
+// delta is negative or NaN.
+func (c *Counter) Add(delta float64) float64 {
+	if !(delta >= 0) {
+		panic("metrics: counter cannot decrease in value")
+	}
+	return c.v.Add(delta)
+}
+
+// Value returns the current value of the counter.
+func (c *Counter) Value() float64 { return c.v.Load() }
+
+// GaugeVec is a set of gauges sharing a name and partitioned by label values.
+type GaugeVec struct {
+	f *family
+}
+
+// With returns the gauge for the given label values, creating it on first
+// use. It panics if the number of values does not match the label names.
+func (v *GaugeVec) With(labelValues ...string) *Gauge {
+	return v.f.with(labelValues).(*Gauge)
+}
+
+// CounterVec is a set of counters sharing a name and partitioned by label
+// values.
+type CounterVec struct {
+	f *family
+}
+
+// With returns the counter for the given label values, creating it on first
+// use. It panics if the number of values does not match the label names.
+func (v *CounterVec) With(labelValues ...string) *Counter {
+	return v.f.with(labelValues).(*Counter)
+}
@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGauge(t *testing.T) {
+	var g Gauge
+	g.Set(1.5)
+	if result := g.Add(2); result != 3.5 {
+		t.Errorf("Expected %v, got %v", 3.5, result)
+	}
+	if result := g.Sub(4); result != -0.5 {
+		t.Errorf("Expected %v, got %v", -0.5, result)
+	}
+	g.Inc()
+	g.Inc()
+	g.Dec()
+	if result := g.Value(); result != 0.5 {
+		t.Errorf("Expected %v, got %v", 0.5, result)
+	}
+}
+
+func TestCounter(t *testing.T) {
+	var c Counter
+	c.Inc()
+	if result := c.Add(1.5); result != 2.5 {
+		t.Errorf("Expected %v, got %v", 2.5, result)
+	}
+	if result := c.Value(); result != 2.5 {
+		t.Errorf("Expected %v, got %v", 2.5, result)
+	}
+}
+
+func TestCounterNegativeAdd(t *testing.T) {
+	var c Counter
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected panic on negative Add")
+		}
+		if result := c.Value(); result != 0 {
+			t.Errorf("Expected %v, got %v", 0, result)
+		}
+	}()
+	c.Add(-1)
+}
+
+func TestCounterNaNAdd(t *testing.T) {
+	var c Counter
+	c.Inc()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected panic on NaN Add")
+		}
+		if result := c.Value(); result != 1 {
+			t.Errorf("Expected %v, got %v", 1, result)
+		}
+	}()
+	c.Add(math.NaN())
+}
+
+func TestCounterConcurrent(t *testing.T) {
+	const itemsCount = 10000
+	const gorotines = 10
+	r := NewRegistry()
+	v, err := r.NewCounterVec("requests_total", "", "worker")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan bool)
+	for i := 0; i < gorotines; i++ {
+		go func() {
+			for j := 0; j < itemsCount; j++ {
+				v.With("shared").Inc()
+			}
+			done <- true
+		}()
+	}
+	for i := 0; i < gorotines; i++ {
+		<-done
+	}
+	if result := v.With("shared").Value(); result != itemsCount*gorotines {
+		t.Errorf("Expected %v, got %v", itemsCount*gorotines, result)
+	}
+}
+
+func TestVecLabelCount(t *testing.T) {
+	r := NewRegistry()
+	v, err := r.NewGaugeVec("temperature", "", "room", "sensor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.With("kitchen", "a") != v.With("kitchen", "a") {
+		t.Errorf("Expected the same gauge for the same label values")
+	}
+	if v.With("kitchen", "a") == v.With("kitchen", "b") {
+		t.Errorf("Expected different gauges for different label values")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected panic on label count mismatch")
+		}
+	}()
+	v.With("kitchen")
+}
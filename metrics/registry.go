@@ -0,0 +1,242 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// metric is implemented by *Gauge and *Counter.
+type metric interface {
+	Value() float64
+}
+
+// series is one labelled child of a family.
+type series struct {
+	labelValues []string
+	m           metric
+}
+
+// family is every series sharing a metric name.
+type family struct {
+	name       string
+	help       string
+	typ        string
+	labelNames []string
+	newMetric  func() metric
+
+	mu     sync.RWMutex
+	series map[string]*series
+}
+
+func (f *family) with(labelValues []string) metric {
+	if len(labelValues) != len(f.labelNames) {
+		panic(fmt.Sprintf("metrics: %s has %d label names but got %d values",
+			f.name, len(f.labelNames), len(labelValues)))
+	}
+	key := seriesKey(labelValues)
+	f.mu.RLock()
+	s, ok := f.series[key]
+	f.mu.RUnlock()
+	if ok {
+		return s.m
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, ok := f.series[key]; ok {
+		return s.m
+	}
+	s = &series{labelValues: append([]string(nil), labelValues...), m: f.newMetric()}
+	f.series[key] = s
+	return s.m
+}
+
+// seriesKey returns a map key for labelValues. Every value is prefixed with
+// its length, so no choice of values, which are arbitrary strings, can make
+// two different lists share a key.
+func seriesKey(labelValues []string) string {
+	var b []byte
+	for _, v := range labelValues {
+		b = strconv.AppendInt(b, int64(len(v)), 10)
+		b = append(b, ':')
+		b = append(b, v...)
+	}
+	return string(b)
+}
+
+// Registry is a set of uniquely named metric families.
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// NewGauge registers and returns a gauge without labels.
+func (r *Registry) NewGauge(name, help string) (*Gauge, error) {
+	f, err := r.register(name, help, "gauge", nil, func() metric { return new(Gauge) })
+	if err != nil {
+		return nil, err
+	}
+	return f.with(nil).(*Gauge), nil
+}
+
+// NewCounter registers and returns a counter without labels.
+func (r *Registry) NewCounter(name, help string) (*Counter, error) {
+	f, err := r.register(name, help, "counter", nil, func() metric { return new(Counter) })
+	if err != nil {
+		return nil, err
+	}
+	return f.with(nil).(*Counter), nil
+}
+
+// NewGaugeVec registers and returns a gauge partitioned by labelNames.
+func (r *Registry) NewGaugeVec(name, help string, labelNames ...string) (*GaugeVec, error) {
+	f, err := r.register(name, help, "gauge", labelNames, func() metric { return new(Gauge) })
+	if err != nil {
+		return nil, err
+	}
+	return &GaugeVec{f: f}, nil
+}
+
+// NewCounterVec registers and returns a counter partitioned by labelNames.
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) (*CounterVec, error) {
+	f, err := r.register(name, help, "counter", labelNames, func() metric { return new(Counter) })
+	if err != nil {
+		return nil, err
+	}
+	return &CounterVec{f: f}, nil
+}
+
+func (r *Registry) register(name, help, typ string, labelNames []string, newMetric func() metric) (*family, error) {
+	if !validName(name, true) {
+		return nil, fmt.Errorf("metrics: invalid metric name %q", name)
+	}
+	seen := make(map[string]bool, len(labelNames))
+	for _, l := range labelNames {
+		if !validName(l, false) || strings.HasPrefix(l, "__") {
+			return nil, fmt.Errorf("metrics: invalid label name %q for %s", l, name)
+		}
+		if seen[l] {
+			return nil, fmt.Errorf("metrics: duplicate label name %q for %s", l, name)
+		}
+		seen[l] = true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.families[name]; ok {
+		return nil, fmt.Errorf("metrics: %s is already registered", name)
+	}
+	f := &family{
+		name:       name,
+		help:       help,
+		typ:        typ,
+		labelNames: append([]string(nil), labelNames...),
+		newMetric:  newMetric,
+		series:     make(map[string]*series),
+	}
+	r.families[name] = f
+	return f, nil
+}
+
+// validName reports whether s matches [a-zA-Z_:][a-zA-Z0-9_:]* for metric
+// names, or [a-zA-Z_][a-zA-Z0-9_]* for label names.
+func validName(s string, colon bool) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		case c >= '0' && c <= '9' && i > 0:
+		case c == ':' && colon:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// WriteText writes every registered metric to w in the Prometheus text
+// exposition format, ordered by metric name and then label values.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	families := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mu.Unlock()
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		f.mu.RLock()
+		series := make([]*series, 0, len(f.series))
+		for _, s := range f.series {
+			series = append(series, s)
+		}
+		f.mu.RUnlock()
+		if len(series) == 0 {
+			continue
+		}
+		sort.Slice(series, func(i, j int) bool {
+			a, b := series[i].labelValues, series[j].labelValues
+			for k := range a {
+				if a[k] != b[k] {
+					return a[k] < b[k]
+				}
+			}
+			return false
+		})
+
+		if f.help != "" {
+			fmt.Fprintf(bw, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		}
+		fmt.Fprintf(bw, "# TYPE %s %s\n", f.name, f.typ)
+		for _, s := range series {
+			bw.WriteString(f.name)
+			if len(f.labelNames) > 0 {
+				bw.WriteByte('{')
+				for k, l := range f.labelNames {
+					if k > 0 {
+						bw.WriteByte(',')
+					}
+					fmt.Fprintf(bw, "%s=\"%s\"", l, escapeLabelValue(s.labelValues[k]))
+				}
+				bw.WriteByte('}')
+			}
+			bw.WriteByte(' ')
+			bw.WriteString(formatValue(s.m.Value()))
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string       { return helpEscaper.Replace(s) }
+func escapeLabelValue(s string) string { return labelValueEscaper.Replace(s) }
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWriteText(t *testing.T) {
+	r := NewRegistry()
+	temp, err := r.NewGauge("temperature_celsius", "Current temperature.\nIn \\celsius\\.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reqs, err := r.NewCounterVec("http_requests_total", "Requests served.", "method", "path")
+	if err != nil {
+		t.Fatal(err)
+	}
+	special, err := r.NewGaugeVec("special", "", "kind")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.NewGaugeVec("unused", "Never set.", "kind"); err != nil {
+		t.Fatal(err)
+	}
+
+	temp.Set(21.5)
+	reqs.With("POST", "/api").Add(3)
+	reqs.With("GET", `/say "hi"\`).Inc()
+	special.With("nan").Set(math.NaN())
+	special.With("pos").Set(math.Inf(1))
+	special.With("neg").Set(math.Inf(-1))
+	special.With("big").Set(1e21)
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP http_requests_total Requests served.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",path="/say \"hi\"\\"} 1
+http_requests_total{method="POST",path="/api"} 3
+# TYPE special gauge
+special{kind="big"} 1e+21
+special{kind="nan"} NaN
+special{kind="neg"} -Inf
+special{kind="pos"} +Inf
+# HELP temperature_celsius Current temperature.\nIn \\celsius\\.
+# TYPE temperature_celsius gauge
+temperature_celsius 21.5
+`
+	if result := b.String(); result != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, result)
+	}
+}
+
+func TestRegistrySeriesKeyCollision(t *testing.T) {
+	r := NewRegistry()
+	v, err := r.NewGaugeVec("collide", "", "a", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pairs := [][2]string{{"a\xffb", "c"}, {"a", "b\xffc"}, {"1:a", ""}, {"", "1:a"}, {"1:a1:", "b"}, {"1:a", "1:b"}}
+	for i, p := range pairs {
+		v.With(p[0], p[1]).Set(float64(i))
+	}
+	for i, p := range pairs {
+		if result := v.With(p[0], p[1]).Value(); result != float64(i) {
+			t.Errorf("With(%q, %q): expected %v, got %v", p[0], p[1], float64(i), result)
+		}
+	}
+}
+
+func TestRegistryErrors(t *testing.T) {
+	r := NewRegistry()
+	if _, err := r.NewGauge("up", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.NewCounter("up", ""); err == nil {
+		t.Errorf("Expected error registering a duplicate name")
+	}
+	for _, name := range []string{"", "1up", "up-time", "up time"} {
+		if _, err := r.NewGauge(name, ""); err == nil {
+			t.Errorf("Expected error for metric name %q", name)
+		}
+	}
+	if _, err := r.NewGauge("ns:up_2", ""); err != nil {
+		t.Errorf("Unexpected error for a valid name: %v", err)
+	}
+	for _, labels := range [][]string{{"a:b"}, {"__reserved"}, {"a", "a"}, {""}} {
+		if _, err := r.NewGaugeVec("labelled", "", labels...); err == nil {
+			t.Errorf("Expected error for label names %q", labels)
+		}
+	}
+}
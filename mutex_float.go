@@ -120,3 +120,27 @@ func (a *atomicFloat64Mutex) cas(old, new float64) bool {
 	a.mu.Unlock()
 	return e
 }
+
+type atomicVec3Mutex struct {
+	mu sync.RWMutex
+	v  Vec3
+}
+
+func newAtomicVec3Mutex(initial Vec3) *atomicVec3Mutex {
+	return &atomicVec3Mutex{v: initial}
+}
+
+// Load atomically loads the current vector.
+func (a *atomicVec3Mutex) load() Vec3 {
+	a.mu.RLock()
+	v := a.v
+	a.mu.RUnlock()
+	return v
+}
+
+// Store atomically stores new into the vector.
+func (a *atomicVec3Mutex) store(new Vec3) {
+	a.mu.Lock()
+	a.v = new
+	a.mu.Unlock()
+}
@@ -0,0 +1,148 @@
+package atomic_float
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// ObservedFloat64 is a Float64 that calls registered observers after every
+// modification. The zero value has no observers.
+//
+// Observers run on the goroutine that made the modification, after the new
+// value is visible to other goroutines and without any lock held, so they may
+// read or modify x themselves. Observers of concurrent modifications can run
+// concurrently and in any order. Without observers, modifications cost one
+// extra atomic load over Float64.
+//
+// An ObservedFloat64 must not be copied.
+type ObservedFloat64 struct {
+	v Float64
+
+	mu        sync.Mutex // serializes changes to observers
+	observers atomic.Pointer[[]*observer]
+}
+
+type observer struct {
+	fn func(old, new float64)
+}
+
+// Load atomically loads and returns the value stored in x.
+func (x *ObservedFloat64) Load() float64 { return x.v.Load() }
+
+// Store atomically stores val into x and notifies observers.
+func (x *ObservedFloat64) Store(val float64) {
+	old := x.v.Swap(val)
+	x.emit(old, val)
+}
+
+// Add atomically adds delta to x, notifies observers and returns the new
+// value.
+func (x *ObservedFloat64) Add(delta float64) (new float64) {
+	for {
+		old := x.v.Load()
+		new = old + delta
+		if x.v.CompareAndSwap(old, new) {
+			x.emit(old, new)
+			return new
+		}
+	}
+}
+
+// Swap atomically stores new into x, notifies observers and returns the
+// previous value.
+func (x *ObservedFloat64) Swap(new float64) (old float64) {
+	old = x.v.Swap(new)
+	x.emit(old, new)
+	return old
+}
+
+// CompareAndSwap executes the compare-and-swap operation for x and notifies
+// observers if the value was swapped.
+func (x *ObservedFloat64) CompareAndSwap(old, new float64) (swapped bool) {
+	if swapped = x.v.CompareAndSwap(old, new); swapped {
+		x.emit(old, new)
+	}
+	return swapped
+}
+
+func (x *ObservedFloat64) emit(old, new float64) {
+	obs := x.observers.Load()
+	if obs == nil {
+		return
+	}
+	for _, o := range *obs {
+		o.fn(old, new)
+	}
+}
+
+// Observe registers fn to be called with the previous and the new value after
+// every modification of x. The returned function unregisters it.
+func (x *ObservedFloat64) Observe(fn func(old, new float64)) (cancel func()) {
+	o := &observer{fn: fn}
+	x.mu.Lock()
+	var obs []*observer
+	if p := x.observers.Load(); p != nil {
+		obs = append(obs, *p...)
+	}
+	obs = append(obs, o)
+	x.observers.Store(&obs)
+	x.mu.Unlock()
+
+	var once sync.Once
+	return func() { once.Do(func() { x.unobserve(o) }) }
+}
+
+func (x *ObservedFloat64) unobserve(o *observer) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	var obs []*observer
+	for _, p := range *x.observers.Load() {
+		if p != o {
+			obs = append(obs, p)
+		}
+	}
+	if len(obs) == 0 {
+		x.observers.Store(nil)
+		return
+	}
+	x.observers.Store(&obs)
+}
+
+// OnAbove calls fn with the new value when x rises above threshold. It does
+// not fire again until x has fallen to threshold-hysteresis or below, so a
+// value hovering around the threshold does not trigger repeatedly. If x is
+// already above threshold when OnAbove is called, fn first fires after such
+// a fall and another rise. The returned function unregisters fn.
+func (x *ObservedFloat64) OnAbove(threshold, hysteresis float64, fn func(v float64)) (cancel func()) {
+	var armed atomic.Bool
+	armed.Store(!(x.v.Load() > threshold))
+	return x.Observe(func(_, new float64) {
+		switch {
+		case new > threshold:
+			if armed.CompareAndSwap(true, false) {
+				fn(new)
+			}
+		case new <= threshold-hysteresis:
+			armed.Store(true)
+		}
+	})
+}
+
+// OnBelow calls fn with the new value when x falls below threshold. It does
+// not fire again until x has risen to threshold+hysteresis or above. If x is
+// already below threshold when OnBelow is called, fn first fires after such
+// a rise and another fall. The returned function unregisters fn.
+func (x *ObservedFloat64) OnBelow(threshold, hysteresis float64, fn func(v float64)) (cancel func()) {
+	var armed atomic.Bool
+	armed.Store(!(x.v.Load() < threshold))
+	return x.Observe(func(_, new float64) {
+		switch {
+		case new < threshold:
+			if armed.CompareAndSwap(true, false) {
+				fn(new)
+			}
+		case new >= threshold+hysteresis:
+			armed.Store(true)
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package atomic_float
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestObservedFloat64(t *testing.T) {
+	var x ObservedFloat64
+	type change struct{ old, new float64 }
+	var changes []change
+	cancel := x.Observe(func(old, new float64) { changes = append(changes, change{old, new}) })
+
+	x.Store(1)
+	x.Add(2)
+	x.Swap(-1)
+	x.CompareAndSwap(0, 5)
+	x.CompareAndSwap(-1, 5)
+	cancel()
+	cancel()
+	x.Store(7)
+
+	want := []change{{0, 1}, {1, 3}, {3, -1}, {-1, 5}}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("Change %d: expected %v, got %v", i, want[i], changes[i])
+		}
+	}
+	if result := x.Load(); result != 7 {
+		t.Errorf("Expected %v, got %v", 7, result)
+	}
+}
+
+func TestObservedFloat64Reentrant(t *testing.T) {
+	var x ObservedFloat64
+	x.Observe(func(old, new float64) {
+		if new > 10 {
+			x.Store(10)
+		}
+	})
+	x.Add(15)
+	if result := x.Load(); result != 10 {
+		t.Errorf("Expected %v, got %v", 10, result)
+	}
+}
+
+func TestOnAboveHysteresis(t *testing.T) {
+	var x ObservedFloat64
+	var fired []float64
+	x.OnAbove(100, 10, func(v float64) { fired = append(fired, v) })
+
+	for _, v := range []float64{50, 101, 120, 99, 95, 105, 90, 110} {
+		x.Store(v)
+	}
+	want := []float64{101, 110}
+	if len(fired) != len(want) || fired[0] != want[0] || fired[1] != want[1] {
+		t.Errorf("Expected %v, got %v", want, fired)
+	}
+}
+
+func TestOnBelowHysteresis(t *testing.T) {
+	var x ObservedFloat64
+	x.Store(5)
+	var fired []float64
+	x.OnBelow(0, 1, func(v float64) { fired = append(fired, v) })
+
+	for _, v := range []float64{-1, -2, 0.5, -0.5, 1, -3} {
+		x.Store(v)
+	}
+	want := []float64{-1, -3}
+	if len(fired) != len(want) || fired[0] != want[0] || fired[1] != want[1] {
+		t.Errorf("Expected %v, got %v", want, fired)
+	}
+}
+
+func TestOnAboveAlreadyAbove(t *testing.T) {
+	var x ObservedFloat64
+	x.Store(200)
+	var fired int
+	x.OnAbove(100, 0, func(float64) { fired++ })
+	x.Store(150)
+	x.Store(50)
+	x.Store(150)
+	if fired != 1 {
+		t.Errorf("Expected %v, got %v", 1, fired)
+	}
+}
+
+func TestObservedFloat64Concurrent(t *testing.T) {
+	const itemsCount = 10000
+	const gorotines = 10
+	var x ObservedFloat64
+	var sum Float64
+	var calls atomic.Int64
+	x.Observe(func(old, new float64) {
+		sum.Add(new - old)
+		calls.Add(1)
+	})
+
+	done := make(chan bool)
+	for i := 0; i < gorotines; i++ {
+		go func() {
+			for j := 0; j < itemsCount; j++ {
+				x.Add(0.5)
+			}
+			done <- true
+		}()
+	}
+	for i := 0; i < gorotines; i++ {
+		<-done
+	}
+	if result := calls.Load(); result != itemsCount*gorotines {
+		t.Errorf("Expected %v calls, got %v", itemsCount*gorotines, result)
+	}
+	if result := sum.Load(); result != x.Load() {
+		t.Errorf("Expected observed deltas to sum to %v, got %v", x.Load(), result)
+	}
+}
@@ -0,0 +1,47 @@
+//go:build !race
+
+package atomic_float
+
+// Memory ordering variants. amd64 is a TSO architecture: every load already
+// has acquire semantics and every store has release semantics, so the stores
+// are a plain MOV in assembly instead of the XCHG that sync/atomic's
+// sequentially consistent Store compiles to. The assembly is invisible to the
+// race detector, so race builds use ordering_other.go instead.
+
+// LoadAcquireFloat32 atomically loads *ptr. No later load or store may be
+// reordered before it.
+func LoadAcquireFloat32(ptr *float32) float32 { return LoadFloat32(ptr) }
+
+// LoadRelaxedFloat32 atomically loads *ptr without ordering guarantees for
+// other memory operations.
+func LoadRelaxedFloat32(ptr *float32) float32 { return LoadFloat32(ptr) }
+
+// StoreReleaseFloat32 atomically stores val into *ptr. No earlier load or
+// store may be reordered after it.
+func StoreReleaseFloat32(ptr *float32, val float32) { asmStoreReleaseFloat32(ptr, val) }
+
+// StoreRelaxedFloat32 atomically stores val into *ptr without ordering
+// guarantees for other memory operations.
+func StoreRelaxedFloat32(ptr *float32, val float32) { asmStoreRelaxedFloat32(ptr, val) }
+
+// LoadAcquireFloat64 atomically loads *ptr. No later load or store may be
+// reordered before it.
+func LoadAcquireFloat64(ptr *float64) float64 { return LoadFloat64(ptr) }
+
+// LoadRelaxedFloat64 atomically loads *ptr without ordering guarantees for
+// other memory operations.
+func LoadRelaxedFloat64(ptr *float64) float64 { return LoadFloat64(ptr) }
+
+// StoreReleaseFloat64 atomically stores val into *ptr. No earlier load or
+// store may be reordered after it.
+func StoreReleaseFloat64(ptr *float64, val float64) {
+	checkAligned64(ptr)
+	asmStoreReleaseFloat64(ptr, val)
+}
+
+// StoreRelaxedFloat64 atomically stores val into *ptr without ordering
+// guarantees for other memory operations.
+func StoreRelaxedFloat64(ptr *float64, val float64) {
+	checkAligned64(ptr)
+	asmStoreRelaxedFloat64(ptr, val)
+}
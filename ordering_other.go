@@ -0,0 +1,66 @@
+//go:build !amd64 || race
+
+package atomic_float
+
+import (
+	"math"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Memory ordering variants for race builds and for architectures without a
+// dedicated implementation. sync/atomic operations are sequentially
+// consistent, which is stronger than each variant requires, and are visible
+// to the race detector.
+
+// LoadAcquireFloat32 atomically loads *ptr. No later load or store may be
+// reordered before it.
+func LoadAcquireFloat32(ptr *float32) float32 {
+	return math.Float32frombits(atomic.LoadUint32((*uint32)(unsafe.Pointer(ptr))))
+}
+
+// LoadRelaxedFloat32 atomically loads *ptr without ordering guarantees for
+// other memory operations.
+func LoadRelaxedFloat32(ptr *float32) float32 {
+	return math.Float32frombits(atomic.LoadUint32((*uint32)(unsafe.Pointer(ptr))))
+}
+
+// StoreReleaseFloat32 atomically stores val into *ptr. No earlier load or
+// store may be reordered after it.
+func StoreReleaseFloat32(ptr *float32, val float32) {
+	atomic.StoreUint32((*uint32)(unsafe.Pointer(ptr)), math.Float32bits(val))
+}
+
+// StoreRelaxedFloat32 atomically stores val into *ptr without ordering
+// guarantees for other memory operations.
+func StoreRelaxedFloat32(ptr *float32, val float32) {
+	atomic.StoreUint32((*uint32)(unsafe.Pointer(ptr)), math.Float32bits(val))
+}
+
+// LoadAcquireFloat64 atomically loads *ptr. No later load or store may be
+// reordered before it.
+func LoadAcquireFloat64(ptr *float64) float64 {
+	checkAligned64(ptr)
+	return math.Float64frombits(atomic.LoadUint64((*uint64)(unsafe.Pointer(ptr))))
+}
+
+// LoadRelaxedFloat64 atomically loads *ptr without ordering guarantees for
+// other memory operations.
+func LoadRelaxedFloat64(ptr *float64) float64 {
+	checkAligned64(ptr)
+	return math.Float64frombits(atomic.LoadUint64((*uint64)(unsafe.Pointer(ptr))))
+}
+
+// StoreReleaseFloat64 atomically stores val into *ptr. No earlier load or
+// store may be reordered after it.
+func StoreReleaseFloat64(ptr *float64, val float64) {
+	checkAligned64(ptr)
+	atomic.StoreUint64((*uint64)(unsafe.Pointer(ptr)), math.Float64bits(val))
+}
+
+// StoreRelaxedFloat64 atomically stores val into *ptr without ordering
+// guarantees for other memory operations.
+func StoreRelaxedFloat64(ptr *float64, val float64) {
+	checkAligned64(ptr)
+	atomic.StoreUint64((*uint64)(unsafe.Pointer(ptr)), math.Float64bits(val))
+}
@@ -0,0 +1,52 @@
+//go:build !(atomicfloat_asm && race)
+
+package atomic_float
+
+import (
+	"runtime"
+	"testing"
+)
+
+// TestReleaseAcquirePublish publishes ordinary, non-atomic data with
+// StoreRelease and reads it after LoadAcquire. Under go test -race the
+// release/acquire pair must be visible to the race detector, or the plain
+// accesses to data are reported as a race. The atomicfloat_asm build is
+// uninstrumented assembly, so the test is left out of that build under race.
+func TestReleaseAcquirePublish(t *testing.T) {
+	const rounds = 1000
+	var data [4]float64
+	var flag Float64
+	var flag32 Float32
+
+	done := make(chan bool)
+	go func() {
+		for i := 1; i <= rounds; i++ {
+			for flag.LoadAcquire() != float64(i) {
+				runtime.Gosched()
+			}
+			for j := range data {
+				if result := data[j]; result != float64(i+j) {
+					t.Errorf("Expected %v, got %v", float64(i+j), result)
+				}
+				data[j] = -data[j]
+			}
+			flag32.StoreRelease(float32(i))
+		}
+		done <- true
+	}()
+	for i := 1; i <= rounds; i++ {
+		for j := range data {
+			data[j] = float64(i + j)
+		}
+		flag.StoreRelease(float64(i))
+		for flag32.LoadAcquire() != float32(i) {
+			runtime.Gosched()
+		}
+		for j := range data {
+			if result := data[j]; result != -float64(i+j) {
+				t.Errorf("Expected %v, got %v", -float64(i+j), result)
+			}
+		}
+	}
+	<-done
+}
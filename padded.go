@@ -0,0 +1,35 @@
+package atomic_float
+
+import "unsafe"
+
+// PaddedFloat32 is a Float32 padded to occupy a full cache line, so that
+// values laid out after it, e.g. the next element of an array of
+// PaddedFloat32, never share its cache line. Use it for values updated
+// concurrently by different CPUs to avoid false sharing.
+//
+// A PaddedFloat32 must not be copied.
+type PaddedFloat32 struct {
+	Float32
+	_ [cacheLineSize - unsafe.Sizeof(Float32{})]byte
+}
+
+// NewPaddedFloat32 returns a new PaddedFloat32 holding val.
+func NewPaddedFloat32(val float32) *PaddedFloat32 {
+	return &PaddedFloat32{Float32: Float32{v: val}}
+}
+
+// PaddedFloat64 is a Float64 padded to occupy a full cache line, so that
+// values laid out after it, e.g. the next element of an array of
+// PaddedFloat64, never share its cache line. Use it for values updated
+// concurrently by different CPUs to avoid false sharing.
+//
+// A PaddedFloat64 must not be copied.
+type PaddedFloat64 struct {
+	Float64
+	_ [cacheLineSize - unsafe.Sizeof(Float64{})]byte
+}
+
+// NewPaddedFloat64 returns a new PaddedFloat64 holding val.
+func NewPaddedFloat64(val float64) *PaddedFloat64 {
+	return &PaddedFloat64{Float64: Float64{v: val}}
+}
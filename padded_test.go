@@ -0,0 +1,55 @@
+package atomic_float
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestPaddedFloat64Layout(t *testing.T) {
+	var counters [4]PaddedFloat64
+	if size := unsafe.Sizeof(counters[0]); size != cacheLineSize {
+		t.Errorf("Expected size %v, got %v", cacheLineSize, size)
+	}
+	a := uintptr(unsafe.Pointer(&counters[0].v))
+	b := uintptr(unsafe.Pointer(&counters[1].v))
+	if b-a != cacheLineSize {
+		t.Errorf("Expected adjacent values %v bytes apart, got %v", cacheLineSize, b-a)
+	}
+	if a%8 != 0 {
+		t.Errorf("Expected 8-byte aligned value, got address %#x", a)
+	}
+}
+
+func TestPaddedFloat32Layout(t *testing.T) {
+	var counters [2]PaddedFloat32
+	if size := unsafe.Sizeof(counters[0]); size != cacheLineSize {
+		t.Errorf("Expected size %v, got %v", cacheLineSize, size)
+	}
+}
+
+func TestPaddedFloat64(t *testing.T) {
+	f := NewPaddedFloat64(1.5)
+	if result := f.Add(1); result != 2.5 {
+		t.Errorf("Expected %v, got %v", 2.5, result)
+	}
+	if result := f.Swap(-1); result != 2.5 {
+		t.Errorf("Expected %v, got %v", 2.5, result)
+	}
+	if result := f.CompareAndSwap(-1, 3); result != true {
+		t.Errorf("Expected %v, got %v", true, result)
+	}
+	if result := f.Load(); result != 3 {
+		t.Errorf("Expected %v, got %v", 3, result)
+	}
+}
+
+func TestPaddedFloat32(t *testing.T) {
+	f := NewPaddedFloat32(1.5)
+	if result := f.Add(1); result != 2.5 {
+		t.Errorf("Expected %v, got %v", 2.5, result)
+	}
+	f.Store(-1)
+	if result := f.Load(); result != -1 {
+		t.Errorf("Expected %v, got %v", -1, result)
+	}
+}
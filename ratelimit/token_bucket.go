@@ -0,0 +1,159 @@
+// Package ratelimit provides a lock-free token bucket rate limiter built on
+// atomic floats.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	atomic_float "atomic-float"
+)
+
+var (
+	// ErrBurstExceeded is returned by WaitN when more tokens are requested
+	// than the bucket can ever hold.
+	ErrBurstExceeded = errors.New("ratelimit: requested tokens exceed burst size")
+	// ErrInvalidTokens is returned by WaitN for a token count that is not
+	// positive and finite.
+	ErrInvalidTokens = errors.New("ratelimit: token count must be positive and finite")
+)
+
+// Clock supplies the current time and timers to a TokenBucket.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// TokenBucket is a rate limiter refilled continuously at rate tokens per
+// second up to burst tokens. Token amounts may be fractional.
+//
+// The whole bucket state is a single atomic float: the time, in seconds since
+// the bucket was created, at which the bucket was (or will be) empty. The
+// number of tokens available at time now is min(burst, (now-empty)*rate), so
+// taking tokens is one CompareAndSwap and no lock is needed.
+//
+// A TokenBucket must not be copied.
+type TokenBucket struct {
+	rate  float64
+	burst float64
+	clock Clock
+	base  time.Time
+	empty atomic_float.Float64
+}
+
+// NewTokenBucket returns a full bucket refilled at rate tokens per second and
+// holding at most burst tokens.
+func NewTokenBucket(rate, burst float64) *TokenBucket {
+	return NewTokenBucketWithClock(rate, burst, systemClock{})
+}
+
+// NewTokenBucketWithClock is like NewTokenBucket but reads time from clock.
+func NewTokenBucketWithClock(rate, burst float64, clock Clock) *TokenBucket {
+	if !(rate > 0) || !(burst > 0) {
+		panic("ratelimit: rate and burst must be positive")
+	}
+	tb := &TokenBucket{rate: rate, burst: burst, clock: clock, base: clock.Now()}
+	tb.empty.Store(-burst / rate)
+	return tb
+}
+
+// now returns the current time in seconds since the bucket was created.
+func (tb *TokenBucket) now() float64 {
+	return tb.clock.Now().Sub(tb.base).Seconds()
+}
+
+// Tokens returns the number of tokens currently available. It is negative
+// while outstanding reservations are still being paid off.
+func (tb *TokenBucket) Tokens() float64 {
+	return math.Min(tb.burst, (tb.now()-tb.empty.Load())*tb.rate)
+}
+
+// Allow reports whether one token is available and takes it if so.
+func (tb *TokenBucket) Allow() bool {
+	return tb.AllowN(1)
+}
+
+// validTokens reports whether n is a token count the bucket can take: NaN
+// would poison the state, and zero, negative or infinite counts would hand
+// out or destroy tokens without limit.
+func validTokens(n float64) bool {
+	return n > 0 && !math.IsInf(n, 1)
+}
+
+// AllowN reports whether n tokens are available and takes them if so. It
+// returns false if n is not positive and finite.
+func (tb *TokenBucket) AllowN(n float64) bool {
+	if !validTokens(n) {
+		return false
+	}
+	now := tb.now()
+	for {
+		old := tb.empty.Load()
+		new := math.Max(old, now-tb.burst/tb.rate) + n/tb.rate
+		if new > now {
+			return false
+		}
+		if tb.empty.CompareAndSwap(old, new) {
+			return true
+		}
+	}
+}
+
+// Reserve takes n tokens, going into debt if necessary, and returns how long
+// the caller must wait before acting on them. It returns false without taking
+// anything if n exceeds the burst size and can never be satisfied, or if n is
+// not positive and finite.
+func (tb *TokenBucket) Reserve(n float64) (delay time.Duration, ok bool) {
+	if !validTokens(n) || n > tb.burst {
+		return 0, false
+	}
+	now := tb.now()
+	for {
+		old := tb.empty.Load()
+		new := math.Max(old, now-tb.burst/tb.rate) + n/tb.rate
+		if tb.empty.CompareAndSwap(old, new) {
+			if new <= now {
+				return 0, true
+			}
+			return time.Duration((new - now) * float64(time.Second)), true
+		}
+	}
+}
+
+// Wait blocks until one token is available and takes it.
+func (tb *TokenBucket) Wait(ctx context.Context) error {
+	return tb.WaitN(ctx, 1)
+}
+
+// WaitN blocks until n tokens are available and takes them. If ctx is done
+// first the reserved tokens are returned to the bucket and ctx.Err() is
+// returned.
+func (tb *TokenBucket) WaitN(ctx context.Context, n float64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !validTokens(n) {
+		return ErrInvalidTokens
+	}
+	delay, ok := tb.Reserve(n)
+	if !ok {
+		return ErrBurstExceeded
+	}
+	if delay == 0 {
+		return nil
+	}
+	select {
+	case <-tb.clock.After(delay):
+		return nil
+	case <-ctx.Done():
+		tb.empty.Add(-n / tb.rate)
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,216 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced Clock.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeTimer
+}
+
+type fakeTimer struct {
+	at time.Time
+	c  chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1700000000, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeTimer{at: c.now.Add(d), c: ch})
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if !w.at.After(c.now) {
+			w.c <- c.now
+		} else {
+			pending = append(pending, w)
+		}
+	}
+	c.waiters = pending
+}
+
+func (c *fakeClock) pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+func TestTokenBucketAllow(t *testing.T) {
+	clock := newFakeClock()
+	tb := NewTokenBucketWithClock(2, 3, clock)
+	for i := 0; i < 3; i++ {
+		if !tb.Allow() {
+			t.Fatalf("Allow %d: expected token from a full bucket", i)
+		}
+	}
+	if tb.Allow() {
+		t.Errorf("Expected empty bucket to refuse")
+	}
+	clock.Advance(250 * time.Millisecond)
+	if tb.Allow() {
+		t.Errorf("Expected refusal with %v tokens", tb.Tokens())
+	}
+	clock.Advance(250 * time.Millisecond)
+	if !tb.Allow() {
+		t.Errorf("Expected a token after refilling %v tokens", tb.Tokens())
+	}
+	clock.Advance(time.Hour)
+	if result := tb.Tokens(); result != 3 {
+		t.Errorf("Expected refill to saturate at %v, got %v", 3, result)
+	}
+}
+
+func TestTokenBucketAllowNFractional(t *testing.T) {
+	clock := newFakeClock()
+	tb := NewTokenBucketWithClock(1, 1, clock)
+	if !tb.AllowN(0.75) {
+		t.Fatalf("Expected 0.75 tokens from a full bucket")
+	}
+	if tb.AllowN(0.5) {
+		t.Errorf("Expected refusal with %v tokens", tb.Tokens())
+	}
+	if !tb.AllowN(0.25) {
+		t.Errorf("Expected the remaining 0.25 tokens")
+	}
+	clock.Advance(500 * time.Millisecond)
+	if result := tb.Tokens(); result != 0.5 {
+		t.Errorf("Expected %v, got %v", 0.5, result)
+	}
+}
+
+func TestTokenBucketReserve(t *testing.T) {
+	clock := newFakeClock()
+	tb := NewTokenBucketWithClock(8, 1, clock)
+	if delay, ok := tb.Reserve(1); !ok || delay != 0 {
+		t.Errorf("Expected 0, true, got %v, %v", delay, ok)
+	}
+	if delay, ok := tb.Reserve(1); !ok || delay != 125*time.Millisecond {
+		t.Errorf("Expected %v, true, got %v, %v", 125*time.Millisecond, delay, ok)
+	}
+	if delay, ok := tb.Reserve(0.5); !ok || delay != 187500*time.Microsecond {
+		t.Errorf("Expected %v, true, got %v, %v", 187500*time.Microsecond, delay, ok)
+	}
+	if result := tb.Tokens(); result != -1.5 {
+		t.Errorf("Expected %v, got %v", -1.5, result)
+	}
+	if _, ok := tb.Reserve(2); ok {
+		t.Errorf("Expected reservation above burst to fail")
+	}
+}
+
+func TestTokenBucketInvalidTokens(t *testing.T) {
+	clock := newFakeClock()
+	tb := NewTokenBucketWithClock(1, 2, clock)
+	for _, n := range []float64{math.NaN(), -1, 0, math.Inf(1), math.Inf(-1)} {
+		if tb.AllowN(n) {
+			t.Errorf("AllowN(%v): expected false", n)
+		}
+		if _, ok := tb.Reserve(n); ok {
+			t.Errorf("Reserve(%v): expected false", n)
+		}
+		if err := tb.WaitN(context.Background(), n); err != ErrInvalidTokens {
+			t.Errorf("WaitN(%v): expected %v, got %v", n, ErrInvalidTokens, err)
+		}
+	}
+	if result := tb.Tokens(); result != 2 {
+		t.Errorf("Expected the bucket to stay full with %v tokens, got %v", 2, result)
+	}
+}
+
+func TestTokenBucketWait(t *testing.T) {
+	clock := newFakeClock()
+	tb := NewTokenBucketWithClock(4, 1, clock)
+	if err := tb.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait on a full bucket: %v", err)
+	}
+
+	done := make(chan error)
+	go func() { done <- tb.Wait(context.Background()) }()
+	for clock.pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(200 * time.Millisecond)
+	select {
+	case err := <-done:
+		t.Fatalf("Wait returned %v before the token was refilled", err)
+	default:
+	}
+	clock.Advance(50 * time.Millisecond)
+	if err := <-done; err != nil {
+		t.Errorf("Wait: %v", err)
+	}
+
+	if err := tb.WaitN(context.Background(), 2); err != ErrBurstExceeded {
+		t.Errorf("Expected %v, got %v", ErrBurstExceeded, err)
+	}
+}
+
+func TestTokenBucketWaitCanceled(t *testing.T) {
+	clock := newFakeClock()
+	tb := NewTokenBucketWithClock(1, 1, clock)
+	tb.Allow()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- tb.Wait(ctx) }()
+	for clock.pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected %v, got %v", context.Canceled, err)
+	}
+	if result := tb.Tokens(); result != 0 {
+		t.Errorf("Expected the canceled reservation to be refunded, got %v tokens", result)
+	}
+}
+
+func TestTokenBucketAllowConcurrent(t *testing.T) {
+	const gorotines = 10
+	const burst = 1000
+	tb := NewTokenBucketWithClock(1, burst, newFakeClock())
+
+	allowed := make(chan int)
+	for i := 0; i < gorotines; i++ {
+		go func() {
+			n := 0
+			for j := 0; j < burst; j++ {
+				if tb.Allow() {
+					n++
+				}
+			}
+			allowed <- n
+		}()
+	}
+	total := 0
+	for i := 0; i < gorotines; i++ {
+		total += <-allowed
+	}
+	if total != burst {
+		t.Errorf("Expected %v allowed, got %v", burst, total)
+	}
+}
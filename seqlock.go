@@ -0,0 +1,40 @@
+package atomic_float
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// seqlock lets a group of atomic floats be read consistently without
+// blocking writers. Writers are serialized by mu and bump seq before and after
+// storing, so seq is odd while a write is in progress. Readers load seq, load
+// the values atomically and retry if seq changed in between.
+type seqlock struct {
+	mu  sync.Mutex
+	seq atomic.Uint64
+}
+
+// lock serializes writers.
+func (l *seqlock) lock()   { l.mu.Lock() }
+func (l *seqlock) unlock() { l.mu.Unlock() }
+
+// beginWrite and endWrite bracket the stores of a writer holding the lock.
+func (l *seqlock) beginWrite() { l.seq.Add(1) }
+func (l *seqlock) endWrite()   { l.seq.Add(1) }
+
+// beginRead waits until no write is in progress and returns the sequence
+// number to pass to retry.
+func (l *seqlock) beginRead() uint64 {
+	for {
+		if s := l.seq.Load(); s&1 == 0 {
+			return s
+		}
+		runtime.Gosched()
+	}
+}
+
+// retry reports whether a write happened since beginRead returned s.
+func (l *seqlock) retry(s uint64) bool {
+	return l.seq.Load() != s
+}
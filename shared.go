@@ -0,0 +1,51 @@
+package atomic_float
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Operations on float64 values stored in arbitrary memory, such as a file
+// mapped into several processes. The value at offset off occupies
+// b[off:off+8] in native byte order and must be 8-byte aligned; the functions
+// panic if it is misaligned or out of range.
+
+// float64At returns a pointer to the float64 at b[off:off+8].
+func float64At(b []byte, off int) *float64 {
+	if off < 0 || off > len(b)-8 {
+		panic(fmt.Sprintf("atomic_float: offset %d out of range for %d bytes", off, len(b)))
+	}
+	p := unsafe.Pointer(&b[off])
+	if uintptr(p)%8 != 0 {
+		panic(fmt.Sprintf("atomic_float: offset %d is not 8-byte aligned (address %p)", off, p))
+	}
+	return (*float64)(p)
+}
+
+// LoadFloat64At atomically loads the float64 at b[off:off+8].
+func LoadFloat64At(b []byte, off int) float64 {
+	return LoadFloat64(float64At(b, off))
+}
+
+// StoreFloat64At atomically stores val into b[off:off+8].
+func StoreFloat64At(b []byte, off int, val float64) {
+	StoreFloat64(float64At(b, off), val)
+}
+
+// AddFloat64At atomically adds delta to the float64 at b[off:off+8] and
+// returns the new value.
+func AddFloat64At(b []byte, off int, delta float64) float64 {
+	return AddFloat64(float64At(b, off), delta)
+}
+
+// SwapFloat64At atomically stores new into b[off:off+8] and returns the
+// previous value.
+func SwapFloat64At(b []byte, off int, new float64) float64 {
+	return SwapFloat64(float64At(b, off), new)
+}
+
+// CompareAndSwapFloat64At executes the compare-and-swap operation for the
+// float64 at b[off:off+8].
+func CompareAndSwapFloat64At(b []byte, off int, old, new float64) bool {
+	return CompareAndSwapFloat64(float64At(b, off), old, new)
+}
@@ -0,0 +1,81 @@
+package atomic_float
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// SharedFloat64Array is a fixed-length array of float64 values backed by a
+// file mapped with MAP_SHARED. Every process that opens the same file shares
+// the values and can update them atomically.
+type SharedFloat64Array struct {
+	f    *os.File
+	data []byte
+}
+
+// OpenSharedFloat64Array maps the first n float64 values of the file at path,
+// creating the file or extending it with zeros as needed.
+func OpenSharedFloat64Array(path string, n int) (*SharedFloat64Array, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("atomic_float: invalid shared array length %d", n)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	size := n * 8
+	if fi.Size() < int64(size) {
+		if err := f.Truncate(int64(size)); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("atomic_float: mmap %s: %w", path, err)
+	}
+	return &SharedFloat64Array{f: f, data: data}, nil
+}
+
+// Len returns the number of values in the array.
+func (a *SharedFloat64Array) Len() int { return len(a.data) / 8 }
+
+// Load atomically loads the i'th value.
+func (a *SharedFloat64Array) Load(i int) float64 { return LoadFloat64At(a.data, i*8) }
+
+// Store atomically stores val into the i'th value.
+func (a *SharedFloat64Array) Store(i int, val float64) { StoreFloat64At(a.data, i*8, val) }
+
+// Add atomically adds delta to the i'th value and returns the new value.
+func (a *SharedFloat64Array) Add(i int, delta float64) float64 {
+	return AddFloat64At(a.data, i*8, delta)
+}
+
+// Swap atomically stores new into the i'th value and returns the previous
+// value.
+func (a *SharedFloat64Array) Swap(i int, new float64) float64 {
+	return SwapFloat64At(a.data, i*8, new)
+}
+
+// CompareAndSwap executes the compare-and-swap operation for the i'th value.
+func (a *SharedFloat64Array) CompareAndSwap(i int, old, new float64) bool {
+	return CompareAndSwapFloat64At(a.data, i*8, old, new)
+}
+
+// Close unmaps the array and closes the file. The array must not be used
+// afterwards.
+func (a *SharedFloat64Array) Close() error {
+	err := syscall.Munmap(a.data)
+	a.data = nil
+	if cerr := a.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
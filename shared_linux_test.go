@@ -0,0 +1,89 @@
+package atomic_float
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	sharedFileEnv = "ATOMIC_FLOAT_SHARED_FILE"
+	sharedAdds    = 100000
+)
+
+// TestSharedFloat64ArrayHelper is run in a child process by
+// TestSharedFloat64ArrayCrossProcess.
+func TestSharedFloat64ArrayHelper(t *testing.T) {
+	path := os.Getenv(sharedFileEnv)
+	if path == "" {
+		t.Skip("helper process only")
+	}
+	a, err := OpenSharedFloat64Array(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	for i := 0; i < sharedAdds; i++ {
+		a.Add(0, 1)
+		a.Add(1, 0.5)
+	}
+}
+
+func TestSharedFloat64ArrayCrossProcess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shared")
+	a, err := OpenSharedFloat64Array(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	if result := a.Len(); result != 2 {
+		t.Errorf("Expected %v, got %v", 2, result)
+	}
+
+	child := exec.Command(os.Args[0], "-test.run=^TestSharedFloat64ArrayHelper$")
+	child.Env = append(os.Environ(), sharedFileEnv+"="+path)
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+	if err := child.Start(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < sharedAdds; i++ {
+		a.Add(0, 1)
+	}
+	if err := child.Wait(); err != nil {
+		t.Fatalf("helper process: %v", err)
+	}
+
+	if result := a.Load(0); result != 2*sharedAdds {
+		t.Errorf("Expected %v, got %v", 2*sharedAdds, result)
+	}
+	if result := a.Load(1); result != 0.5*sharedAdds {
+		t.Errorf("Expected %v, got %v", 0.5*sharedAdds, result)
+	}
+
+	// A second mapping of the same file sees the same values.
+	b, err := OpenSharedFloat64Array(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	b.Store(1, -3)
+	if result := a.Load(1); result != -3 {
+		t.Errorf("Expected %v, got %v", -3, result)
+	}
+}
+
+func TestSharedFloat64ArrayBounds(t *testing.T) {
+	a, err := OpenSharedFloat64Array(filepath.Join(t.TempDir(), "shared"), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected panic for index out of range")
+		}
+	}()
+	a.Load(3)
+}
@@ -0,0 +1,64 @@
+package atomic_float
+
+import (
+	"testing"
+)
+
+func TestFloat64At(t *testing.T) {
+	b := make([]byte, 24)
+	StoreFloat64At(b, 8, 1.5)
+	if result := LoadFloat64At(b, 8); result != 1.5 {
+		t.Errorf("Expected %v, got %v", 1.5, result)
+	}
+	if result := AddFloat64At(b, 8, 2); result != 3.5 {
+		t.Errorf("Expected %v, got %v", 3.5, result)
+	}
+	if result := SwapFloat64At(b, 8, -1); result != 3.5 {
+		t.Errorf("Expected %v, got %v", 3.5, result)
+	}
+	if result := CompareAndSwapFloat64At(b, 8, -1, 4); result != true {
+		t.Errorf("Expected %v, got %v", true, result)
+	}
+	if result := LoadFloat64At(b, 0) + LoadFloat64At(b, 16); result != 0 {
+		t.Errorf("Expected neighbouring values to be untouched, got %v", result)
+	}
+	if result := LoadFloat64At(b, 8); result != 4 {
+		t.Errorf("Expected %v, got %v", 4, result)
+	}
+}
+
+func TestFloat64AtInvalid(t *testing.T) {
+	b := make([]byte, 24)
+	for _, off := range []int{-8, 1, 4, 17, 24} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected panic for offset %d", off)
+				}
+			}()
+			AddFloat64At(b, off, 1)
+		}()
+	}
+}
+
+func TestFloat64AtConcurrent(t *testing.T) {
+	const itemsCount = 10000
+	const gorotines = 10
+	b := make([]byte, 16)
+
+	done := make(chan bool)
+	for i := 0; i < gorotines; i++ {
+		go func() {
+			for j := 0; j < itemsCount; j++ {
+				AddFloat64At(b, 8, 2.5)
+			}
+			done <- true
+		}()
+	}
+	for i := 0; i < gorotines; i++ {
+		<-done
+	}
+	if result := LoadFloat64At(b, 8); result != float64(itemsCount*gorotines)*2.5 {
+		t.Errorf("Expected %v, got %v", float64(itemsCount*gorotines)*2.5, result)
+	}
+}
@@ -0,0 +1,386 @@
+// Package sketch provides a concurrent quantile sketch for values such as
+// request latencies. It follows DDSketch: values are counted in buckets whose
+// bounds grow geometrically, so every quantile is reported with a bounded
+// relative error. All bucket counts, the count and the sum are atomic floats
+// from the parent package, so Add never takes a lock.
+package sketch
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	atomic_float "atomic-float"
+)
+
+// Values whose magnitude is outside [MinIndexable, MaxIndexable] are counted
+// in the zero bucket or the outermost bucket respectively.
+const (
+	MinIndexable = 1e-9
+	MaxIndexable = 1e12
+)
+
+// MinAlpha is the finest relative accuracy a sketch supports. The number of
+// buckets grows as 1/alpha; at MinAlpha a sketch has about 24,000 buckets of
+// each sign, 390 KB in total.
+const MinAlpha = 0.001
+
+var (
+	// ErrAlpha is returned by New for a relative accuracy outside
+	// [MinAlpha, 1).
+	ErrAlpha = errors.New("sketch: relative accuracy must be in [0.001, 1)")
+	// ErrMismatch is returned by Merge for sketches of different accuracy.
+	ErrMismatch = errors.New("sketch: cannot merge sketches with different accuracy")
+	// ErrFormat is returned by UnmarshalBinary for malformed data.
+	ErrFormat = errors.New("sketch: invalid binary data")
+)
+
+// Sketch estimates quantiles of the values added to it. It is safe for
+// concurrent use and must not be copied.
+type Sketch struct {
+	alpha    float64
+	gamma    float64
+	lnGamma  float64
+	minIndex int
+
+	// pos[i] and neg[i] count values whose magnitude falls in bucket
+	// i+minIndex; zero counts values too small to index.
+	pos  []atomic_float.Float64
+	neg  []atomic_float.Float64
+	zero atomic_float.Float64
+
+	count atomic_float.Float64
+	sum   atomic_float.Float64
+	min   atomic_float.Float64
+	max   atomic_float.Float64
+}
+
+// New returns an empty sketch whose quantiles are within a relative error of
+// alpha of the true value, e.g. 0.01 for 1%. alpha must be at least MinAlpha.
+func New(alpha float64) (*Sketch, error) {
+	if !(alpha >= MinAlpha && alpha < 1) {
+		return nil, ErrAlpha
+	}
+	s := &Sketch{}
+	s.init(alpha)
+	return s, nil
+}
+
+func (s *Sketch) init(alpha float64) {
+	s.alpha = alpha
+	s.gamma = (1 + alpha) / (1 - alpha)
+	s.lnGamma = math.Log(s.gamma)
+	s.minIndex = int(math.Ceil(math.Log(MinIndexable) / s.lnGamma))
+	n := int(math.Ceil(math.Log(MaxIndexable)/s.lnGamma)) - s.minIndex + 1
+	s.pos = make([]atomic_float.Float64, n)
+	s.neg = make([]atomic_float.Float64, n)
+	s.zero.Store(0)
+	s.count.Store(0)
+	s.sum.Store(0)
+	s.min.Store(math.Inf(1))
+	s.max.Store(math.Inf(-1))
+}
+
+// RelativeAccuracy returns the alpha the sketch was created with.
+func (s *Sketch) RelativeAccuracy() float64 { return s.alpha }
+
+// bucket returns the bucket for a positive magnitude m >= MinIndexable.
+func (s *Sketch) bucket(m float64) int {
+	i := int(math.Ceil(math.Log(m)/s.lnGamma)) - s.minIndex
+	return max(0, min(i, len(s.pos)-1))
+}
+
+// value returns the representative value of bucket i, which is within alpha
+// of every magnitude counted in it.
+func (s *Sketch) value(i int) float64 {
+	return 2 * math.Pow(s.gamma, float64(i+s.minIndex)) / (s.gamma + 1)
+}
+
+// Add records v. NaN is ignored.
+func (s *Sketch) Add(v float64) {
+	if math.IsNaN(v) {
+		return
+	}
+	// min and max first, so that MarshalBinary, which loads them after the
+	// buckets, never sees a bucket count they do not cover.
+	storeMin(&s.min, v)
+	storeMax(&s.max, v)
+	switch {
+	case v >= MinIndexable:
+		s.pos[s.bucket(v)].Add(1)
+	case v <= -MinIndexable:
+		s.neg[s.bucket(-v)].Add(1)
+	default:
+		s.zero.Add(1)
+	}
+	s.count.Add(1)
+	s.sum.Add(v)
+}
+
+func storeMin(f *atomic_float.Float64, v float64) {
+	for old := f.Load(); v < old; old = f.Load() {
+		if f.CompareAndSwap(old, v) {
+			return
+		}
+	}
+}
+
+func storeMax(f *atomic_float.Float64, v float64) {
+	for old := f.Load(); v > old; old = f.Load() {
+		if f.CompareAndSwap(old, v) {
+			return
+		}
+	}
+}
+
+// Count returns the number of values added.
+func (s *Sketch) Count() float64 { return s.count.Load() }
+
+// Sum returns the sum of the values added.
+func (s *Sketch) Sum() float64 { return s.sum.Load() }
+
+// Min returns the smallest value added, or +Inf if the sketch is empty.
+func (s *Sketch) Min() float64 { return s.min.Load() }
+
+// Max returns the largest value added, or -Inf if the sketch is empty.
+func (s *Sketch) Max() float64 { return s.max.Load() }
+
+// Quantile returns an estimate of the q-quantile, 0 <= q <= 1, of the values
+// added, or NaN if the sketch is empty or q is out of range. The estimate is
+// within a relative error of alpha of the value of rank floor(q*(n-1)).
+func (s *Sketch) Quantile(q float64) float64 {
+	if !(q >= 0 && q <= 1) {
+		return math.NaN()
+	}
+	// Snapshot the buckets so the rank is computed against the same counts
+	// that are scanned, even while Add runs concurrently.
+	neg := make([]float64, len(s.neg))
+	pos := make([]float64, len(s.pos))
+	var n float64
+	for i := range neg {
+		neg[i] = s.neg[i].Load()
+		n += neg[i]
+	}
+	zero := s.zero.Load()
+	n += zero
+	for i := range pos {
+		pos[i] = s.pos[i].Load()
+		n += pos[i]
+	}
+	if n == 0 {
+		return math.NaN()
+	}
+
+	rank := math.Floor(q * (n - 1))
+	var v, seen float64
+	found := false
+	for i := len(neg) - 1; i >= 0 && !found; i-- {
+		if seen += neg[i]; seen > rank {
+			v, found = -s.value(i), true
+		}
+	}
+	if !found {
+		if seen += zero; seen > rank {
+			v, found = 0, true
+		}
+	}
+	for i := 0; i < len(pos) && !found; i++ {
+		if seen += pos[i]; seen > rank {
+			v, found = s.value(i), true
+		}
+	}
+	if !found {
+		// Only reachable through float rounding of very large counts.
+		v = s.Max()
+	}
+	return max(s.Min(), min(v, s.Max()))
+}
+
+// Merge adds the values recorded in other to s. Both sketches must have the
+// same relative accuracy. other may be updated concurrently; values it
+// receives during the merge may or may not be included.
+func (s *Sketch) Merge(other *Sketch) error {
+	if s.alpha != other.alpha {
+		return ErrMismatch
+	}
+	storeMin(&s.min, other.min.Load())
+	storeMax(&s.max, other.max.Load())
+	for i := range other.pos {
+		if c := other.pos[i].Load(); c != 0 {
+			s.pos[i].Add(c)
+		}
+		if c := other.neg[i].Load(); c != 0 {
+			s.neg[i].Add(c)
+		}
+	}
+	s.zero.Add(other.zero.Load())
+	s.count.Add(other.count.Load())
+	s.sum.Add(other.sum.Load())
+	return nil
+}
+
+// Binary format, all integers big-endian:
+//
+//	magic "ADDS", version byte 1
+//	alpha, zero, count, sum, min, max as float64 bits
+//	for the positive then negative store: uvarint number of non-empty
+//	buckets, then per bucket a uvarint index delta and float64 count bits
+const (
+	magic   = "ADDS"
+	version = 1
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler. The count written is
+// that of the buckets written, so the data is consistent even while Add runs
+// concurrently: buckets are read before min and max, which Add updates first.
+func (s *Sketch) MarshalBinary() ([]byte, error) {
+	zero := s.zero.Load()
+	pos, neg := loadStore(s.pos), loadStore(s.neg)
+	count := zero + storeTotal(pos) + storeTotal(neg)
+	b := append([]byte(magic), version)
+	for _, f := range []float64{s.alpha, zero, count, s.sum.Load(), s.min.Load(), s.max.Load()} {
+		b = binary.BigEndian.AppendUint64(b, math.Float64bits(f))
+	}
+	b = appendStore(b, pos)
+	b = appendStore(b, neg)
+	return b, nil
+}
+
+func loadStore(store []atomic_float.Float64) []float64 {
+	counts := make([]float64, len(store))
+	for i := range store {
+		counts[i] = store[i].Load()
+	}
+	return counts
+}
+
+func storeTotal(counts []float64) float64 {
+	var n float64
+	for _, c := range counts {
+		n += c
+	}
+	return n
+}
+
+func appendStore(b []byte, counts []float64) []byte {
+	n := 0
+	for _, c := range counts {
+		if c != 0 {
+			n++
+		}
+	}
+	b = binary.AppendUvarint(b, uint64(n))
+	last := 0
+	for i, c := range counts {
+		if c != 0 {
+			b = binary.AppendUvarint(b, uint64(i-last))
+			b = binary.BigEndian.AppendUint64(b, math.Float64bits(c))
+			last = i
+		}
+	}
+	return b
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It replaces the
+// contents of s, which must not be in concurrent use, including its accuracy.
+// It returns ErrFormat unless the data is consistent: every bucket count is
+// positive and finite, the count is the total of the buckets, and min and
+// max bound the values the buckets can hold.
+func (s *Sketch) UnmarshalBinary(data []byte) error {
+	if len(data) < len(magic)+1+6*8 || string(data[:len(magic)]) != magic {
+		return ErrFormat
+	}
+	if v := data[len(magic)]; v != version {
+		return fmt.Errorf("sketch: unsupported binary version %d", v)
+	}
+	data = data[len(magic)+1:]
+	var f [6]float64
+	for i := range f {
+		f[i] = math.Float64frombits(binary.BigEndian.Uint64(data))
+		data = data[8:]
+	}
+	alpha, zero, count, sum, lo, hi := f[0], f[1], f[2], f[3], f[4], f[5]
+	// The accuracy decides how many buckets init allocates, so it is
+	// checked before anything is allocated for it.
+	if !(alpha >= MinAlpha && alpha < 1) {
+		return ErrFormat
+	}
+	if !(zero >= 0) || math.IsInf(zero, 1) || math.IsNaN(lo) || math.IsNaN(hi) {
+		return ErrFormat
+	}
+	var t Sketch
+	t.init(alpha)
+	var err error
+	if data, err = readStore(data, t.pos); err != nil {
+		return err
+	}
+	if data, err = readStore(data, t.neg); err != nil {
+		return err
+	}
+	if len(data) != 0 {
+		return ErrFormat
+	}
+	pos, neg := loadStore(t.pos), loadStore(t.neg)
+	if count != zero+storeTotal(pos)+storeTotal(neg) {
+		return ErrFormat
+	}
+	if count > 0 {
+		// Classes of values: 0 negative, 1 too small to index, 2 positive.
+		class := func(v float64) int {
+			switch {
+			case v <= -MinIndexable:
+				return 0
+			case v < MinIndexable:
+				return 1
+			}
+			return 2
+		}
+		first, last := 2, 0
+		for c, n := range []float64{storeTotal(neg), zero, storeTotal(pos)} {
+			if n != 0 {
+				first, last = min(first, c), max(last, c)
+			}
+		}
+		if !(lo <= hi) || class(lo) > first || class(hi) < last {
+			return ErrFormat
+		}
+	}
+	s.alpha, s.gamma, s.lnGamma, s.minIndex = t.alpha, t.gamma, t.lnGamma, t.minIndex
+	s.pos, s.neg = t.pos, t.neg
+	s.zero.Store(zero)
+	s.count.Store(count)
+	s.sum.Store(sum)
+	s.min.Store(lo)
+	s.max.Store(hi)
+	return nil
+}
+
+// readStore reads the buckets written by appendStore into store. Indexes
+// must stay in range and strictly increase, and counts must be positive and
+// finite.
+func readStore(data []byte, store []atomic_float.Float64) ([]byte, error) {
+	n, k := binary.Uvarint(data)
+	if k <= 0 || n > uint64(len(store)) {
+		return nil, ErrFormat
+	}
+	data = data[k:]
+	idx := uint64(0)
+	for j := uint64(0); j < n; j++ {
+		d, k := binary.Uvarint(data)
+		if k <= 0 || len(data) < k+8 {
+			return nil, ErrFormat
+		}
+		if (j > 0 && d == 0) || d >= uint64(len(store))-idx {
+			return nil, ErrFormat
+		}
+		idx += d
+		c := math.Float64frombits(binary.BigEndian.Uint64(data[k:]))
+		if !(c > 0) || math.IsInf(c, 1) {
+			return nil, ErrFormat
+		}
+		store[idx].Store(c)
+		data = data[k+8:]
+	}
+	return data, nil
+}
@@ -0,0 +1,300 @@
+package sketch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+var quantiles = []float64{0, 0.01, 0.1, 0.25, 0.5, 0.75, 0.9, 0.99, 0.999, 1}
+
+// checkAccuracy compares every quantile of s against the exact quantile of
+// the sorted values.
+func checkAccuracy(t *testing.T, s *Sketch, sorted []float64) {
+	t.Helper()
+	for _, q := range quantiles {
+		want := sorted[int(q*float64(len(sorted)-1))]
+		result := s.Quantile(q)
+		if math.Abs(result-want) > s.RelativeAccuracy()*math.Abs(want)*(1+1e-9) {
+			t.Errorf("Quantile(%v): expected %v within %v, got %v", q, want, s.RelativeAccuracy(), result)
+		}
+	}
+}
+
+func lognormal(r *rand.Rand, n int) []float64 {
+	vals := make([]float64, n)
+	for i := range vals {
+		vals[i] = math.Exp(r.NormFloat64()*2 - 3)
+	}
+	return vals
+}
+
+func TestNew(t *testing.T) {
+	for _, alpha := range []float64{0, 1, -0.1, math.NaN(), MinAlpha / 2, math.SmallestNonzeroFloat64} {
+		if _, err := New(alpha); err != ErrAlpha {
+			t.Errorf("New(%v): expected %v, got %v", alpha, ErrAlpha, err)
+		}
+	}
+	if s, err := New(MinAlpha); err != nil || len(s.pos) > 25000 {
+		t.Errorf("New(%v): expected at most 25000 buckets, got %v", MinAlpha, err)
+	}
+	s, _ := New(0.01)
+	if result := s.Quantile(0.5); !math.IsNaN(result) {
+		t.Errorf("Expected NaN, got %v", result)
+	}
+}
+
+func TestQuantileAccuracy(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, alpha := range []float64{0.05, 0.01, 0.001} {
+		s, _ := New(alpha)
+		vals := lognormal(r, 100000)
+		for _, v := range vals {
+			s.Add(v)
+		}
+		sort.Float64s(vals)
+		checkAccuracy(t, s, vals)
+		if result := s.Count(); result != float64(len(vals)) {
+			t.Errorf("Expected %v, got %v", len(vals), result)
+		}
+		if s.Min() != vals[0] || s.Max() != vals[len(vals)-1] {
+			t.Errorf("Expected min %v max %v, got %v %v", vals[0], vals[len(vals)-1], s.Min(), s.Max())
+		}
+	}
+}
+
+func TestQuantileSigned(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	s, _ := New(0.01)
+	vals := lognormal(r, 50000)
+	for i := range vals {
+		if i%3 == 0 {
+			vals[i] = -vals[i]
+		}
+		if i%100 == 0 {
+			vals[i] = 0
+		}
+		s.Add(vals[i])
+	}
+	s.Add(math.NaN())
+	sort.Float64s(vals)
+	checkAccuracy(t, s, vals)
+}
+
+func TestConcurrentAdd(t *testing.T) {
+	const itemsCount = 20000
+	const gorotines = 8
+	r := rand.New(rand.NewSource(3))
+	vals := lognormal(r, itemsCount*gorotines)
+	s, _ := New(0.01)
+
+	done := make(chan bool)
+	for i := 0; i < gorotines; i++ {
+		part := vals[i*itemsCount : (i+1)*itemsCount]
+		go func() {
+			for _, v := range part {
+				s.Add(v)
+			}
+			done <- true
+		}()
+	}
+	for i := 0; i < gorotines; i++ {
+		<-done
+	}
+
+	if result := s.Count(); result != float64(len(vals)) {
+		t.Errorf("Expected %v, got %v", len(vals), result)
+	}
+	sort.Float64s(vals)
+	checkAccuracy(t, s, vals)
+}
+
+func TestMerge(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	vals := lognormal(r, 40000)
+	a, _ := New(0.01)
+	b, _ := New(0.01)
+	for i, v := range vals {
+		if i%2 == 0 {
+			a.Add(v)
+		} else {
+			b.Add(-v)
+		}
+		if i%2 != 0 {
+			vals[i] = -v
+		}
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	sort.Float64s(vals)
+	checkAccuracy(t, a, vals)
+
+	c, _ := New(0.02)
+	if err := a.Merge(c); err != ErrMismatch {
+		t.Errorf("Expected %v, got %v", ErrMismatch, err)
+	}
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	s, _ := New(0.01)
+	for i, v := range lognormal(r, 10000) {
+		if i%4 == 0 {
+			v = -v
+		}
+		s.Add(v)
+	}
+	s.Add(0)
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var d Sketch
+	if err := d.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if d.RelativeAccuracy() != s.RelativeAccuracy() || d.Count() != s.Count() || d.Sum() != s.Sum() ||
+		d.Min() != s.Min() || d.Max() != s.Max() {
+		t.Errorf("Expected summary to round-trip")
+	}
+	for _, q := range quantiles {
+		if want, result := s.Quantile(q), d.Quantile(q); want != result {
+			t.Errorf("Quantile(%v): expected %v, got %v", q, want, result)
+		}
+	}
+
+	for _, bad := range [][]byte{nil, data[:10], data[:len(data)-1], append(append([]byte{}, data...), 0)} {
+		if err := d.UnmarshalBinary(bad); err == nil {
+			t.Errorf("Expected error for %d bytes", len(bad))
+		}
+	}
+}
+
+// TestUnmarshalHostileAlpha checks that a short payload claiming a tiny
+// accuracy is rejected instead of allocating buckets for it.
+func TestUnmarshalHostileAlpha(t *testing.T) {
+	s, _ := New(0.01)
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, alpha := range []float64{math.SmallestNonzeroFloat64, 1e-300, MinAlpha / 2} {
+		bad := append([]byte{}, data...)
+		binary.BigEndian.PutUint64(bad[len(magic)+1:], math.Float64bits(alpha))
+		var d Sketch
+		allocs := testing.AllocsPerRun(1, func() {
+			if err := d.UnmarshalBinary(bad); err != ErrFormat {
+				t.Errorf("alpha %v: expected %v, got %v", alpha, ErrFormat, err)
+			}
+		})
+		if allocs != 0 {
+			t.Errorf("alpha %v: expected no allocations, got %v", alpha, allocs)
+		}
+	}
+}
+
+// bucketEntry is one bucket of the binary format: an index delta and a count.
+type bucketEntry struct {
+	d uint64
+	c float64
+}
+
+// payload encodes a sketch with accuracy 0.01 in the binary format, with the
+// given fields and positive buckets and no negative ones.
+func payload(zero, count, sum, lo, hi float64, pos ...bucketEntry) []byte {
+	b := append([]byte(magic), version)
+	for _, f := range []float64{0.01, zero, count, sum, lo, hi} {
+		b = binary.BigEndian.AppendUint64(b, math.Float64bits(f))
+	}
+	b = binary.AppendUvarint(b, uint64(len(pos)))
+	for _, e := range pos {
+		b = binary.AppendUvarint(b, e.d)
+		b = binary.BigEndian.AppendUint64(b, math.Float64bits(e.c))
+	}
+	return binary.AppendUvarint(b, 0)
+}
+
+func TestUnmarshalMalformed(t *testing.T) {
+	inf := math.Inf(1)
+	var s Sketch
+	if err := s.UnmarshalBinary(payload(1, 4, 5, 0, 2, bucketEntry{3, 1}, bucketEntry{5, 2})); err != nil {
+		t.Fatalf("Expected a valid payload to decode, got %v", err)
+	}
+	if err := s.UnmarshalBinary(payload(0, 0, 0, inf, -inf)); err != nil {
+		t.Fatalf("Expected an empty sketch to decode, got %v", err)
+	}
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{"index wraps around", payload(0, 2, 2, 1, 1, bucketEntry{1, 1}, bucketEntry{math.MaxUint64, 1})},
+		{"index past the end", payload(0, 1, 1, 1, 1, bucketEntry{1 << 20, 1})},
+		{"repeated index", payload(0, 2, 2, 1, 1, bucketEntry{3, 1}, bucketEntry{0, 1})},
+		{"negative count", payload(0, -1, 1, 1, 1, bucketEntry{3, -1})},
+		{"zero count", payload(0, 0, 1, 1, 1, bucketEntry{3, 0})},
+		{"NaN count", payload(0, math.NaN(), 1, 1, 1, bucketEntry{3, math.NaN()})},
+		{"infinite count", payload(0, inf, 1, 1, 1, bucketEntry{3, inf})},
+		{"negative zero bucket", payload(-1, -1, 0, inf, -inf)},
+		{"infinite zero bucket", payload(inf, inf, 0, 0, 0)},
+		{"count does not match", payload(1, 3, 5, 0, 2, bucketEntry{3, 1})},
+		{"NaN count total", payload(0, math.NaN(), 0, inf, -inf)},
+		{"min above max", payload(0, 1, 1, 2, 1, bucketEntry{3, 1})},
+		{"NaN min", payload(0, 1, 1, math.NaN(), 1, bucketEntry{3, 1})},
+		{"max below positive bucket", payload(0, 1, 1, -2, -1, bucketEntry{3, 1})},
+		{"min above zero bucket", payload(1, 1, 0, 1, 1)},
+	} {
+		if err := s.UnmarshalBinary(tc.data); err != ErrFormat {
+			t.Errorf("%s: expected %v, got %v", tc.name, ErrFormat, err)
+		}
+	}
+}
+
+// FuzzUnmarshalBinary checks that whatever UnmarshalBinary accepts marshals
+// back to data it accepts again, unchanged.
+func FuzzUnmarshalBinary(f *testing.F) {
+	r := rand.New(rand.NewSource(6))
+	s, _ := New(0.05)
+	for _, v := range lognormal(r, 100) {
+		s.Add(v)
+		s.Add(-v / 2)
+	}
+	s.Add(0)
+	data, _ := s.MarshalBinary()
+	f.Add(data)
+	f.Add(payload(1, 4, 5, 0, 2, bucketEntry{3, 1}, bucketEntry{5, 2}))
+	f.Add(payload(0, 0, 0, math.Inf(1), math.Inf(-1)))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var s Sketch
+		if s.UnmarshalBinary(data) != nil {
+			return
+		}
+		s.Quantile(0.5)
+		b, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var d Sketch
+		if err := d.UnmarshalBinary(b); err != nil {
+			t.Fatalf("Expected re-marshaled data to decode, got %v", err)
+		}
+		if b2, _ := d.MarshalBinary(); !bytes.Equal(b, b2) {
+			t.Errorf("Expected a stable encoding")
+		}
+	})
+}
+
+func BenchmarkAdd(b *testing.B) {
+	s, _ := New(0.01)
+	b.RunParallel(func(pb *testing.PB) {
+		v := 0.001
+		for pb.Next() {
+			s.Add(v)
+			v *= 1.0001
+		}
+	})
+}
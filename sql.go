@@ -0,0 +1,146 @@
+package atomic_float
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// scanFloat converts a value returned by a database/sql driver to a float of
+// the given bit size.
+func scanFloat(src any, bitSize int) (float64, error) {
+	var f float64
+	switch v := src.(type) {
+	case float64:
+		f = v
+	case float32:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	case []byte:
+		return strconv.ParseFloat(string(v), bitSize)
+	case string:
+		return strconv.ParseFloat(v, bitSize)
+	case nil:
+		return 0, fmt.Errorf("atomic_float: Scan: cannot scan NULL into a float%d", bitSize)
+	default:
+		return 0, fmt.Errorf("atomic_float: Scan: unsupported type %T", src)
+	}
+	if bitSize == 32 && math.Abs(f) > math.MaxFloat32 && !math.IsInf(f, 0) {
+		return 0, fmt.Errorf("atomic_float: Scan: %v out of range for float32", f)
+	}
+	return f, nil
+}
+
+// Scan implements sql.Scanner. It accepts floating point, integer and
+// numeric text column values; NULL is an error.
+func (x *Float32) Scan(src any) error {
+	f, err := scanFloat(src, 32)
+	if err != nil {
+		return err
+	}
+	x.Store(float32(f))
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (x *Float32) Value() (driver.Value, error) {
+	return float64(x.Load()), nil
+}
+
+// Scan implements sql.Scanner. It accepts floating point, integer and
+// numeric text column values; NULL is an error, use NullFloat64 for nullable
+// columns.
+func (x *Float64) Scan(src any) error {
+	f, err := scanFloat(src, 64)
+	if err != nil {
+		return err
+	}
+	x.Store(f)
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (x *Float64) Value() (driver.Value, error) {
+	return x.Load(), nil
+}
+
+// nullBits is the NaN bit pattern that NullFloat64 reserves for NULL.
+const nullBits = 0x7ff8_4e55_4c4c_0000
+
+// NullFloat64 is an atomically accessed float64 that may be NULL, for
+// nullable SQL columns. The zero value is NULL.
+//
+// The value and its validity are held in one word, so they always change
+// together: NULL is the NaN with bit pattern 0x7ff84e554c4c0000, stored with
+// all bits flipped against that pattern so that zero memory reads as NULL.
+// Storing that particular NaN stores the quiet NaN returned by math.NaN
+// instead, so a stored value never reads back as NULL.
+//
+// A NullFloat64 must not be copied.
+type NullFloat64 struct {
+	v Float64
+}
+
+// NewNullFloat64 returns a new non-NULL NullFloat64 holding val. Use
+// new(NullFloat64) for one that starts out NULL.
+func NewNullFloat64(val float64) *NullFloat64 {
+	return &NullFloat64{v: Float64{v: toNull(val)}}
+}
+
+// flipNull converts between a NullFloat64 value and its stored form.
+func flipNull(v float64) float64 {
+	return math.Float64frombits(math.Float64bits(v) ^ nullBits)
+}
+
+// toNull converts a non-NULL value to its stored form, replacing the NaN
+// reserved for NULL with another one.
+func toNull(v float64) float64 {
+	if math.Float64bits(v) == nullBits {
+		v = math.NaN()
+	}
+	return flipNull(v)
+}
+
+// Load atomically loads the value. It returns 0 and false if it is NULL.
+func (x *NullFloat64) Load() (v float64, valid bool) {
+	s := x.v.Load()
+	if math.Float64bits(s) == 0 {
+		return 0, false
+	}
+	return flipNull(s), true
+}
+
+// Store atomically stores a non-NULL value.
+func (x *NullFloat64) Store(v float64) {
+	x.v.Store(toNull(v))
+}
+
+// StoreNull atomically sets the value to NULL.
+func (x *NullFloat64) StoreNull() {
+	x.v.Store(0)
+}
+
+// Scan implements sql.Scanner. NULL column values are stored as NULL.
+func (x *NullFloat64) Scan(src any) error {
+	if src == nil {
+		x.StoreNull()
+		return nil
+	}
+	f, err := scanFloat(src, 64)
+	if err != nil {
+		return err
+	}
+	x.Store(f)
+	return nil
+}
+
+// Value implements driver.Valuer, returning nil for NULL.
+func (x *NullFloat64) Value() (driver.Value, error) {
+	v, valid := x.Load()
+	if !valid {
+		return nil, nil
+	}
+	return v, nil
+}
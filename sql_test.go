@@ -0,0 +1,214 @@
+package atomic_float
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"math"
+	"sync"
+	"testing"
+)
+
+// fakeDriver is a database/sql driver backed by a single in-memory column.
+// Every Exec appends its first argument as a row and every Query returns all
+// rows.
+type fakeDriver struct {
+	mu   sync.Mutex
+	rows []driver.Value
+}
+
+type fakeConn struct{ d *fakeDriver }
+
+type fakeStmt struct{ d *fakeDriver }
+
+type fakeRows struct {
+	rows []driver.Value
+	i    int
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{d}, nil }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return fakeStmt(c), nil }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.rows = append(s.d.rows, args[0])
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	return &fakeRows{rows: append([]driver.Value(nil), s.d.rows...)}, nil
+}
+
+func (r *fakeRows) Columns() []string { return []string{"value"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.i == len(r.rows) {
+		return io.EOF
+	}
+	dest[0] = r.rows[r.i]
+	r.i++
+	return nil
+}
+
+var fake = &fakeDriver{}
+
+func init() {
+	sql.Register("atomic_float_fake", fake)
+}
+
+func openFake(t *testing.T) *sql.DB {
+	fake.mu.Lock()
+	fake.rows = nil
+	fake.mu.Unlock()
+	db, err := sql.Open("atomic_float_fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSQLValuer(t *testing.T) {
+	db := openFake(t)
+	var f64 Float64
+	var f32 Float32
+	var null, valid NullFloat64
+	f64.Store(1.25)
+	f32.Store(-0.5)
+	valid.Store(3)
+	for _, arg := range []any{&f64, &f32, &null, &valid} {
+		if _, err := db.Exec("INSERT", arg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []driver.Value{1.25, -0.5, nil, 3.0}
+	for i, v := range want {
+		if fake.rows[i] != v {
+			t.Errorf("Row %d: expected %v, got %v", i, v, fake.rows[i])
+		}
+	}
+}
+
+func TestSQLScanner(t *testing.T) {
+	db := openFake(t)
+	for _, v := range []any{1.25, int64(-3), []byte("2.5e3"), "0.125"} {
+		if _, err := db.Exec("INSERT", v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var f64 Float64
+	var f32 Float32
+	var got []float64
+	for rows.Next() {
+		if err := rows.Scan(&f64); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, f64.Load())
+	}
+	want := []float64{1.25, -3, 2500, 0.125}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Row %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+
+	if err := db.QueryRow("SELECT").Scan(&f32); err != nil || f32.Load() != 1.25 {
+		t.Errorf("Expected %v, got %v, %v", 1.25, f32.Load(), err)
+	}
+}
+
+func TestSQLScanNull(t *testing.T) {
+	db := openFake(t)
+	if _, err := db.Exec("INSERT", nil); err != nil {
+		t.Fatal(err)
+	}
+	var f Float64
+	if err := db.QueryRow("SELECT").Scan(&f); err == nil {
+		t.Errorf("Expected error scanning NULL into Float64")
+	}
+	var n NullFloat64
+	n.Store(4)
+	if err := db.QueryRow("SELECT").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if v, valid := n.Load(); valid || v != 0 {
+		t.Errorf("Expected 0, false, got %v, %v", v, valid)
+	}
+}
+
+func TestScanErrors(t *testing.T) {
+	var f32 Float32
+	var f64 Float64
+	if err := f64.Scan(true); err == nil {
+		t.Errorf("Expected error scanning bool")
+	}
+	if err := f64.Scan("abc"); err == nil {
+		t.Errorf("Expected error scanning non-numeric text")
+	}
+	if err := f32.Scan(1e300); err == nil {
+		t.Errorf("Expected error scanning out of range value into Float32")
+	}
+	if err := f32.Scan([]byte("1e300")); err == nil {
+		t.Errorf("Expected error scanning out of range text into Float32")
+	}
+}
+
+func TestNullFloat64(t *testing.T) {
+	var n NullFloat64
+	if v, valid := n.Load(); valid || v != 0 {
+		t.Errorf("Expected zero value to be NULL, got %v, %v", v, valid)
+	}
+	for _, v := range []float64{0, math.Copysign(0, -1), 1.5, math.Inf(1), math.NaN()} {
+		n.Store(v)
+		result, valid := n.Load()
+		if !valid || math.Float64bits(result) != math.Float64bits(v) {
+			t.Errorf("Expected %v, true, got %v, %v", v, result, valid)
+		}
+	}
+	n.StoreNull()
+	if _, valid := n.Load(); valid {
+		t.Errorf("Expected NULL after StoreNull")
+	}
+	if v, valid := NewNullFloat64(2.5).Load(); !valid || v != 2.5 {
+		t.Errorf("Expected 2.5, true, got %v, %v", v, valid)
+	}
+}
+
+func TestNullFloat64ReservedNaN(t *testing.T) {
+	reserved := math.Float64frombits(nullBits)
+	var n NullFloat64
+	n.Store(reserved)
+	if v, valid := n.Load(); !valid || !math.IsNaN(v) {
+		t.Errorf("Expected NaN, true after Store, got %v, %v", v, valid)
+	}
+	if err := n.Scan(reserved); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if v, valid := n.Load(); !valid || !math.IsNaN(v) {
+		t.Errorf("Expected NaN, true after Scan, got %v, %v", v, valid)
+	}
+	if v, valid := NewNullFloat64(reserved).Load(); !valid || !math.IsNaN(v) {
+		t.Errorf("Expected NaN, true from NewNullFloat64, got %v, %v", v, valid)
+	}
+	if result, err := n.Value(); err != nil || result == nil {
+		t.Errorf("Expected a NaN value, got %v, %v", result, err)
+	}
+}
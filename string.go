@@ -0,0 +1,36 @@
+package atomic_float
+
+import (
+	"math"
+	"strconv"
+)
+
+// String returns the value of x encoded as a JSON number, so that *Float32
+// satisfies expvar.Var. NaN and infinities are encoded as the JSON strings
+// "NaN", "+Inf" and "-Inf". Only *Float32 has the method, so fmt does not
+// call it for a Float32 field, even of a struct passed by pointer, and prints
+// the field's internals instead; print its address or Load the value.
+func (x *Float32) String() string {
+	return formatJSON(float64(x.Load()), 32)
+}
+
+// String returns the value of x encoded as a JSON number, so that *Float64
+// satisfies expvar.Var. NaN and infinities are encoded as the JSON strings
+// "NaN", "+Inf" and "-Inf". Only *Float64 has the method, so fmt does not
+// call it for a Float64 field, even of a struct passed by pointer, and prints
+// the field's internals instead; print its address or Load the value.
+func (x *Float64) String() string {
+	return formatJSON(x.Load(), 64)
+}
+
+func formatJSON(f float64, bitSize int) string {
+	switch {
+	case math.IsNaN(f):
+		return `"NaN"`
+	case math.IsInf(f, 1):
+		return `"+Inf"`
+	case math.IsInf(f, -1):
+		return `"-Inf"`
+	}
+	return strconv.FormatFloat(f, 'g', -1, bitSize)
+}
@@ -0,0 +1,57 @@
+package atomic_float
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestFloat64String(t *testing.T) {
+	var f Float64
+	for _, tc := range []struct {
+		v    float64
+		want string
+	}{
+		{0, "0"},
+		{-2.5, "-2.5"},
+		{1e21, "1e+21"},
+		{math.SmallestNonzeroFloat64, "5e-324"},
+		{math.NaN(), `"NaN"`},
+		{math.Inf(1), `"+Inf"`},
+		{math.Inf(-1), `"-Inf"`},
+	} {
+		f.Store(tc.v)
+		if result := f.String(); result != tc.want {
+			t.Errorf("Expected %v, got %v", tc.want, result)
+		}
+		if !json.Valid([]byte(f.String())) {
+			t.Errorf("%v is not valid JSON", f.String())
+		}
+	}
+}
+
+func TestFloat32String(t *testing.T) {
+	var f Float32
+	f.Store(0.1)
+	if result := f.String(); result != "0.1" {
+		t.Errorf("Expected %v, got %v", "0.1", result)
+	}
+	f.Store(float32(math.Inf(-1)))
+	if result := f.String(); result != `"-Inf"` {
+		t.Errorf("Expected %v, got %v", `"-Inf"`, result)
+	}
+}
+
+// TestStringAddressable pins down that fmt calls String for a *Float64 but
+// not for a Float64 field.
+func TestStringAddressable(t *testing.T) {
+	s := &snapshot{Name: "cpu"}
+	s.Total.Store(2.5)
+	if result := fmt.Sprint(&s.Total); result != "2.5" {
+		t.Errorf("Expected %v, got %v", "2.5", result)
+	}
+	if result := fmt.Sprint(s); result == "&{cpu 0 2.5}" {
+		t.Errorf("Expected fmt not to call String for fields, got %v", result)
+	}
+}
@@ -0,0 +1,74 @@
+package atomic_float
+
+import (
+	"math"
+	"sort"
+)
+
+// TopK keeps the K largest values offered to it, e.g. the slowest request
+// latencies. Offer is lock-free.
+//
+// Each slot only ever grows: Offer replaces the smallest slot it finds with
+// CompareAndSwap, which fails if the slot changed in the meantime, and since
+// other slots can only have grown the replaced value really was a minimum.
+// So no value larger than the final minimum is ever lost.
+type TopK struct {
+	slots []Float64
+}
+
+// NewTopK returns an empty TopK holding up to k values.
+func NewTopK(k int) *TopK {
+	if k <= 0 {
+		panic("atomic_float: TopK size must be positive")
+	}
+	t := &TopK{slots: make([]Float64, k)}
+	for i := range t.slots {
+		t.slots[i].Store(math.Inf(-1))
+	}
+	return t
+}
+
+// min returns the index and value of the smallest slot.
+func (t *TopK) min() (int, float64) {
+	idx, min := 0, t.slots[0].Load()
+	for i := 1; i < len(t.slots); i++ {
+		if v := t.slots[i].Load(); v < min {
+			idx, min = i, v
+		}
+	}
+	return idx, min
+}
+
+// Offer adds v if it is larger than the smallest value kept, evicting that
+// value, and reports whether v was kept. NaN and -Inf are never kept.
+func (t *TopK) Offer(v float64) bool {
+	for {
+		i, min := t.min()
+		if !(v > min) {
+			return false
+		}
+		if t.slots[i].CompareAndSwap(min, v) {
+			return true
+		}
+	}
+}
+
+// Min returns the smallest value kept, which is the threshold a value must
+// exceed to be kept. It is -Inf until K values have been kept.
+func (t *TopK) Min() float64 {
+	_, min := t.min()
+	return min
+}
+
+// Values returns the values kept, largest first. While Offer runs
+// concurrently each slot is read atomically but not all at the same instant.
+func (t *TopK) Values() []float64 {
+	vals := make([]float64, 0, len(t.slots))
+	for i := range t.slots {
+		if v := t.slots[i].Load(); !math.IsInf(v, -1) {
+			vals = append(vals, v)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(vals)))
+	return vals
+}
@@ -0,0 +1,79 @@
+package atomic_float
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestTopK(t *testing.T) {
+	k := NewTopK(3)
+	if result := k.Min(); !math.IsInf(result, -1) {
+		t.Errorf("Expected %v, got %v", math.Inf(-1), result)
+	}
+	for _, v := range []float64{5, 1, math.NaN(), 3} {
+		k.Offer(v)
+	}
+	if result := k.Values(); len(result) != 3 || result[0] != 5 || result[1] != 3 || result[2] != 1 {
+		t.Errorf("Expected %v, got %v", []float64{5, 3, 1}, result)
+	}
+	if k.Offer(1) {
+		t.Errorf("Expected a value equal to the minimum to be rejected")
+	}
+	if !k.Offer(4) {
+		t.Errorf("Expected %v to be kept", 4)
+	}
+	if result := k.Values(); len(result) != 3 || result[0] != 5 || result[1] != 4 || result[2] != 3 {
+		t.Errorf("Expected %v, got %v", []float64{5, 4, 3}, result)
+	}
+	if result := k.Min(); result != 3 {
+		t.Errorf("Expected %v, got %v", 3, result)
+	}
+}
+
+func TestTopKPartial(t *testing.T) {
+	k := NewTopK(4)
+	k.Offer(2)
+	k.Offer(math.Inf(-1))
+	if result := k.Values(); len(result) != 1 || result[0] != 2 {
+		t.Errorf("Expected %v, got %v", []float64{2}, result)
+	}
+}
+
+// TestTopKConcurrent offers distinct samples from many goroutines and checks
+// that exactly the K largest are kept, so no sample above the minimum is lost.
+func TestTopKConcurrent(t *testing.T) {
+	const itemsCount = 20000
+	const gorotines = 8
+	const size = 16
+	for round := 0; round < 5; round++ {
+		samples := rand.Perm(itemsCount * gorotines)
+		k := NewTopK(size)
+
+		done := make(chan bool)
+		for i := 0; i < gorotines; i++ {
+			part := samples[i*itemsCount : (i+1)*itemsCount]
+			go func() {
+				for _, s := range part {
+					k.Offer(float64(s) / 8)
+				}
+				done <- true
+			}()
+		}
+		for i := 0; i < gorotines; i++ {
+			<-done
+		}
+
+		sort.Sort(sort.Reverse(sort.IntSlice(samples)))
+		result := k.Values()
+		if len(result) != size {
+			t.Fatalf("Expected %v values, got %v", size, len(result))
+		}
+		for i, v := range result {
+			if want := float64(samples[i]) / 8; v != want {
+				t.Errorf("Round %d, rank %d: expected %v, got %v", round, i, want, v)
+			}
+		}
+	}
+}
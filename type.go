@@ -1,5 +1,10 @@
 package atomic_float
 
+import (
+	"math"
+	"sync/atomic"
+)
+
 // Compatable with src/runtime/internal/atomic/types.go
 
 // An Float32 is an atomic float32. The zero value is zero.
@@ -8,16 +13,60 @@ type Float32 struct {
 	v float32
 }
 
+// NewFloat32 returns a new Float32 holding val. The value is set before the
+// pointer is returned, so readers that receive the pointer never observe a
+// zero value, which a separate Store after allocation cannot guarantee.
+//
+// The pointer may be shared between goroutines, but the Float32 it points to
+// must not be copied, e.g. by dereferencing it into another variable.
+func NewFloat32(val float32) *Float32 {
+	return &Float32{v: val}
+}
+
+// Float32FromBits returns a new Float32 holding the float32 with the IEEE 754
+// bit pattern b, preserving NaN payloads.
+func Float32FromBits(b uint32) *Float32 {
+	return NewFloat32(math.Float32frombits(b))
+}
+
 // Load atomically loads and returns the value stored in x.
 //
 //go:nosplit
 func (x *Float32) Load() float32 { return LoadFloat32(&x.v) }
 
+// Bits atomically loads x and returns its IEEE 754 bit pattern.
+//
+//go:nosplit
+func (x *Float32) Bits() uint32 { return math.Float32bits(LoadFloat32(&x.v)) }
+
 // Store atomically stores val into x.
 //
 //go:nosplit
 func (x *Float32) Store(val float32) { StoreFloat32(&x.v, val) }
 
+// LoadAcquire atomically loads x with acquire ordering: later memory
+// operations are not reordered before it.
+//
+//go:nosplit
+func (x *Float32) LoadAcquire() float32 { return LoadAcquireFloat32(&x.v) }
+
+// LoadRelaxed atomically loads x without ordering other memory operations.
+//
+//go:nosplit
+func (x *Float32) LoadRelaxed() float32 { return LoadRelaxedFloat32(&x.v) }
+
+// StoreRelease atomically stores val into x with release ordering: earlier
+// memory operations are not reordered after it.
+//
+//go:nosplit
+func (x *Float32) StoreRelease(val float32) { StoreReleaseFloat32(&x.v, val) }
+
+// StoreRelaxed atomically stores val into x without ordering other memory
+// operations.
+//
+//go:nosplit
+func (x *Float32) StoreRelaxed(val float32) { StoreRelaxedFloat32(&x.v, val) }
+
 // Swap atomically stores new into x and returns the previous value.
 //
 //go:nosplit
@@ -35,6 +84,76 @@ func (x *Float32) CompareAndSwap(old, new float32) (swapped bool) {
 //go:nosplit
 func (x *Float32) Add(delta float32) (new float32) { return AddFloat32(&x.v, delta) }
 
+// Sub atomically subtracts delta from x and returns the new value.
+//
+//go:nosplit
+func (x *Float32) Sub(delta float32) (new float32) { return AddFloat32(&x.v, -delta) }
+
+// CAS is an atomic compare-and-swap.
+//
+// Deprecated: Use CompareAndSwap.
+//
+//go:nosplit
+func (x *Float32) CAS(old, new float32) (swapped bool) { return x.CompareAndSwap(old, new) }
+
+// Negate atomically flips the sign of x and returns the previous value.
+//
+//go:nosplit
+func (x *Float32) Negate() (old float32) { return NegateFloat32(&x.v) }
+
+// Increment atomically moves x to the next representable value towards +Inf
+// and returns the new value. +Inf and NaN are left unchanged.
+//
+//go:nosplit
+func (x *Float32) Increment() (new float32) { return IncrementFloat32(&x.v) }
+
+// Decrement atomically moves x to the next representable value towards -Inf
+// and returns the new value. -Inf and NaN are left unchanged.
+//
+//go:nosplit
+func (x *Float32) Decrement() (new float32) { return DecrementFloat32(&x.v) }
+
+// Abs atomically clears the sign of x and returns the previous value.
+//
+//go:nosplit
+func (x *Float32) Abs() (old float32) { return AbsFloat32(&x.v) }
+
+// SetSign atomically makes x negative if neg is true and positive otherwise,
+// keeping its magnitude, and returns the previous value.
+//
+//go:nosplit
+func (x *Float32) SetSign(neg bool) (old float32) { return SetSignFloat32(&x.v, neg) }
+
+// CopySign atomically gives x the sign of sign, keeping its magnitude, and
+// returns the previous value.
+//
+//go:nosplit
+func (x *Float32) CopySign(sign float32) (old float32) {
+	return SetSignFloat32(&x.v, math.Signbit(float64(sign)))
+}
+
+// FMA atomically computes x = a*b + x with a single rounding and returns the
+// new value.
+//
+//go:nosplit
+func (x *Float32) FMA(a, b float32) (new float32) { return FMAFloat32(&x.v, a, b) }
+
+// AddIfWithin atomically adds delta to x if the result stays within [lo, hi].
+// Otherwise x is left unchanged and its current value is returned with false.
+//
+//go:nosplit
+func (x *Float32) AddIfWithin(delta, lo, hi float32) (new float32, ok bool) {
+	return AddIfWithinFloat32(&x.v, delta, lo, hi)
+}
+
+// AddClamped atomically adds delta to x, saturating at lo and hi, and returns
+// the new value. A NaN result leaves x unchanged; lo > hi panics.
+//
+//go:nosplit
+func (x *Float32) AddClamped(delta, lo, hi float32) (new float32) {
+	return AddClampedFloat32(&x.v, delta, lo, hi)
+}
+
 // Float64 is an atomically accessed float64 value.
 //
 // 8-byte aligned on all platforms, unlike a regular float64.
@@ -46,16 +165,60 @@ type Float64 struct {
 	v      float64
 }
 
+// NewFloat64 returns a new Float64 holding val. The value is set before the
+// pointer is returned, so readers that receive the pointer never observe a
+// zero value, which a separate Store after allocation cannot guarantee.
+//
+// The pointer may be shared between goroutines, but the Float64 it points to
+// must not be copied, e.g. by dereferencing it into another variable.
+func NewFloat64(val float64) *Float64 {
+	return &Float64{v: val}
+}
+
+// Float64FromBits returns a new Float64 holding the float64 with the IEEE 754
+// bit pattern b, preserving NaN payloads.
+func Float64FromBits(b uint64) *Float64 {
+	return NewFloat64(math.Float64frombits(b))
+}
+
 // Load atomically loads and returns the value stored in x.
 //
 //go:nosplit
 func (x *Float64) Load() float64 { return LoadFloat64(&x.v) }
 
+// Bits atomically loads x and returns its IEEE 754 bit pattern.
+//
+//go:nosplit
+func (x *Float64) Bits() uint64 { return math.Float64bits(LoadFloat64(&x.v)) }
+
 // Store atomically stores val into x.
 //
 //go:nosplit
 func (x *Float64) Store(val float64) { StoreFloat64(&x.v, val) }
 
+// LoadAcquire atomically loads x with acquire ordering: later memory
+// operations are not reordered before it.
+//
+//go:nosplit
+func (x *Float64) LoadAcquire() float64 { return LoadAcquireFloat64(&x.v) }
+
+// LoadRelaxed atomically loads x without ordering other memory operations.
+//
+//go:nosplit
+func (x *Float64) LoadRelaxed() float64 { return LoadRelaxedFloat64(&x.v) }
+
+// StoreRelease atomically stores val into x with release ordering: earlier
+// memory operations are not reordered after it.
+//
+//go:nosplit
+func (x *Float64) StoreRelease(val float64) { StoreReleaseFloat64(&x.v, val) }
+
+// StoreRelaxed atomically stores val into x without ordering other memory
+// operations.
+//
+//go:nosplit
+func (x *Float64) StoreRelaxed(val float64) { StoreRelaxedFloat64(&x.v, val) }
+
 // Swap atomically stores new into x and returns the previous value.
 //
 //go:nosplit
@@ -73,6 +236,76 @@ func (x *Float64) CompareAndSwap(old, new float64) (swapped bool) {
 //go:nosplit
 func (x *Float64) Add(delta float64) (new float64) { return AddFloat64(&x.v, delta) }
 
+// Sub atomically subtracts delta from x and returns the new value.
+//
+//go:nosplit
+func (x *Float64) Sub(delta float64) (new float64) { return AddFloat64(&x.v, -delta) }
+
+// CAS is an atomic compare-and-swap.
+//
+// Deprecated: Use CompareAndSwap.
+//
+//go:nosplit
+func (x *Float64) CAS(old, new float64) (swapped bool) { return x.CompareAndSwap(old, new) }
+
+// Negate atomically flips the sign of x and returns the previous value.
+//
+//go:nosplit
+func (x *Float64) Negate() (old float64) { return NegateFloat64(&x.v) }
+
+// Increment atomically moves x to the next representable value towards +Inf
+// and returns the new value. +Inf and NaN are left unchanged.
+//
+//go:nosplit
+func (x *Float64) Increment() (new float64) { return IncrementFloat64(&x.v) }
+
+// Decrement atomically moves x to the next representable value towards -Inf
+// and returns the new value. -Inf and NaN are left unchanged.
+//
+//go:nosplit
+func (x *Float64) Decrement() (new float64) { return DecrementFloat64(&x.v) }
+
+// Abs atomically clears the sign of x and returns the previous value.
+//
+//go:nosplit
+func (x *Float64) Abs() (old float64) { return AbsFloat64(&x.v) }
+
+// SetSign atomically makes x negative if neg is true and positive otherwise,
+// keeping its magnitude, and returns the previous value.
+//
+//go:nosplit
+func (x *Float64) SetSign(neg bool) (old float64) { return SetSignFloat64(&x.v, neg) }
+
+// CopySign atomically gives x the sign of sign, keeping its magnitude, and
+// returns the previous value.
+//
+//go:nosplit
+func (x *Float64) CopySign(sign float64) (old float64) {
+	return SetSignFloat64(&x.v, math.Signbit(float64(sign)))
+}
+
+// FMA atomically computes x = a*b + x with a single rounding and returns the
+// new value.
+//
+//go:nosplit
+func (x *Float64) FMA(a, b float64) (new float64) { return FMAFloat64(&x.v, a, b) }
+
+// AddIfWithin atomically adds delta to x if the result stays within [lo, hi].
+// Otherwise x is left unchanged and its current value is returned with false.
+//
+//go:nosplit
+func (x *Float64) AddIfWithin(delta, lo, hi float64) (new float64, ok bool) {
+	return AddIfWithinFloat64(&x.v, delta, lo, hi)
+}
+
+// AddClamped atomically adds delta to x, saturating at lo and hi, and returns
+// the new value. A NaN result leaves x unchanged; lo > hi panics.
+//
+//go:nosplit
+func (x *Float64) AddClamped(delta, lo, hi float64) (new float64) {
+	return AddClampedFloat64(&x.v, delta, lo, hi)
+}
+
 // Copied from src/runtime/internal/atomic/types.go
 
 // noCopy may be added to structs which must not be copied
@@ -89,6 +322,7 @@ func (*noCopy) Lock()   {}
 func (*noCopy) Unlock() {}
 
 // align64 may be added to structs that must be 64-bit aligned.
-// This struct is recognized by a special case in the compiler
-// and will not work if copied to any other package.
-type align64 struct{}
+// The runtime's align64 is recognized by a special case in the compiler
+// and does not work if copied to any other package, so this one borrows the
+// alignment of sync/atomic.Int64, which has it, through a zero-length array.
+type align64 struct{ _ [0]atomic.Int64 }
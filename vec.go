@@ -0,0 +1,119 @@
+package atomic_float
+
+// Vec3 is a 3D vector.
+type Vec3 struct {
+	X, Y, Z float64
+}
+
+// Vec4 is a 4D vector or a quaternion.
+type Vec4 struct {
+	X, Y, Z, W float64
+}
+
+// AtomicVec3 is an atomically accessed Vec3. The zero value is the zero
+// vector.
+//
+// Writers are serialized; readers do not block and retry if a write
+// overlapped, so Load always returns a vector that was stored as a whole.
+//
+// An AtomicVec3 must not be copied.
+type AtomicVec3 struct {
+	lock seqlock
+	v    Vec3
+}
+
+// Load atomically loads and returns the vector stored in x.
+func (x *AtomicVec3) Load() Vec3 {
+	for {
+		s := x.lock.beginRead()
+		v := Vec3{
+			X: LoadFloat64(&x.v.X),
+			Y: LoadFloat64(&x.v.Y),
+			Z: LoadFloat64(&x.v.Z),
+		}
+		if !x.lock.retry(s) {
+			return v
+		}
+	}
+}
+
+// Store atomically stores v into x.
+func (x *AtomicVec3) Store(v Vec3) {
+	x.lock.lock()
+	x.store(v)
+	x.lock.unlock()
+}
+
+// Update atomically replaces the vector with fn applied to it and returns the
+// new vector. Other writers wait while fn runs.
+func (x *AtomicVec3) Update(fn func(Vec3) Vec3) (new Vec3) {
+	x.lock.lock()
+	defer x.lock.unlock()
+	new = fn(x.v)
+	x.store(new)
+	return new
+}
+
+// store writes v while holding the lock.
+func (x *AtomicVec3) store(v Vec3) {
+	x.lock.beginWrite()
+	StoreFloat64(&x.v.X, v.X)
+	StoreFloat64(&x.v.Y, v.Y)
+	StoreFloat64(&x.v.Z, v.Z)
+	x.lock.endWrite()
+}
+
+// AtomicVec4 is an atomically accessed Vec4. The zero value is the zero
+// vector.
+//
+// Writers are serialized; readers do not block and retry if a write
+// overlapped, so Load always returns a vector that was stored as a whole.
+//
+// An AtomicVec4 must not be copied.
+type AtomicVec4 struct {
+	lock seqlock
+	v    Vec4
+}
+
+// Load atomically loads and returns the vector stored in x.
+func (x *AtomicVec4) Load() Vec4 {
+	for {
+		s := x.lock.beginRead()
+		v := Vec4{
+			X: LoadFloat64(&x.v.X),
+			Y: LoadFloat64(&x.v.Y),
+			Z: LoadFloat64(&x.v.Z),
+			W: LoadFloat64(&x.v.W),
+		}
+		if !x.lock.retry(s) {
+			return v
+		}
+	}
+}
+
+// Store atomically stores v into x.
+func (x *AtomicVec4) Store(v Vec4) {
+	x.lock.lock()
+	x.store(v)
+	x.lock.unlock()
+}
+
+// Update atomically replaces the vector with fn applied to it and returns the
+// new vector. Other writers wait while fn runs.
+func (x *AtomicVec4) Update(fn func(Vec4) Vec4) (new Vec4) {
+	x.lock.lock()
+	defer x.lock.unlock()
+	new = fn(x.v)
+	x.store(new)
+	return new
+}
+
+// store writes v while holding the lock.
+func (x *AtomicVec4) store(v Vec4) {
+	x.lock.beginWrite()
+	StoreFloat64(&x.v.X, v.X)
+	StoreFloat64(&x.v.Y, v.Y)
+	StoreFloat64(&x.v.Z, v.Z)
+	StoreFloat64(&x.v.W, v.W)
+	x.lock.endWrite()
+}
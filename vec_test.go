@@ -0,0 +1,72 @@
+package atomic_float
+
+import (
+	"testing"
+)
+
+func TestAtomicVec3(t *testing.T) {
+	var v AtomicVec3
+	if result := v.Load(); result != (Vec3{}) {
+		t.Errorf("Expected %v, got %v", Vec3{}, result)
+	}
+	v.Store(Vec3{1, 2, 3})
+	if result := v.Load(); result != (Vec3{1, 2, 3}) {
+		t.Errorf("Expected %v, got %v", Vec3{1, 2, 3}, result)
+	}
+	result := v.Update(func(p Vec3) Vec3 { return Vec3{p.X + 1, p.Y * 2, -p.Z} })
+	if want := (Vec3{2, 4, -3}); result != want || v.Load() != want {
+		t.Errorf("Expected %v, got %v and %v", want, result, v.Load())
+	}
+}
+
+func TestAtomicVec4(t *testing.T) {
+	var q AtomicVec4
+	q.Store(Vec4{0, 0, 0, 1})
+	result := q.Update(func(p Vec4) Vec4 { return Vec4{p.W, p.X, p.Y, p.Z} })
+	if want := (Vec4{1, 0, 0, 0}); result != want || q.Load() != want {
+		t.Errorf("Expected %v, got %v and %v", want, result, q.Load())
+	}
+}
+
+// TestAtomicVec3Consistent checks that readers never see a torn vector.
+func TestAtomicVec3Consistent(t *testing.T) {
+	const itemsCount = 5000
+	const gorotines = 4
+	var v AtomicVec3
+
+	stop := make(chan bool)
+	done := make(chan bool)
+	for i := 0; i < gorotines; i++ {
+		go func() {
+			for {
+				select {
+				case <-stop:
+					done <- true
+					return
+				default:
+				}
+				if p := v.Load(); p.Y != 2*p.X || p.Z != 3*p.X {
+					t.Errorf("Torn vector %v", p)
+				}
+			}
+		}()
+	}
+	for i := 0; i < gorotines; i++ {
+		go func() {
+			for j := 0; j < itemsCount; j++ {
+				v.Update(func(p Vec3) Vec3 { return Vec3{p.X + 1, p.Y + 2, p.Z + 3} })
+			}
+			done <- true
+		}()
+	}
+	for i := 0; i < gorotines; i++ {
+		<-done
+	}
+	close(stop)
+	for i := 0; i < gorotines; i++ {
+		<-done
+	}
+	if result := v.Load(); result.X != itemsCount*gorotines {
+		t.Errorf("Expected %v, got %v", itemsCount*gorotines, result.X)
+	}
+}
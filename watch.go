@@ -0,0 +1,149 @@
+package atomic_float
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// WatchableFloat64 is a Float64 whose changes can be waited for. The zero
+// value is ready to use.
+//
+// Modifications cost one extra atomic load over Float64 while nobody is
+// waiting; only when there are waiters or subscribers do they take a lock to
+// wake them.
+//
+// A WatchableFloat64 must not be copied.
+type WatchableFloat64 struct {
+	v Float64
+
+	// watchers counts WaitUntil calls and Changes subscriptions in progress.
+	watchers atomic.Int32
+
+	mu      sync.Mutex
+	changed chan struct{} // closed on the next change, nil if nobody waits
+	subs    map[chan float64]struct{}
+}
+
+// Load atomically loads and returns the value stored in x.
+func (x *WatchableFloat64) Load() float64 { return x.v.Load() }
+
+// Store atomically stores val into x and wakes watchers.
+func (x *WatchableFloat64) Store(val float64) {
+	x.v.Store(val)
+	x.notify()
+}
+
+// Add atomically adds delta to x, wakes watchers and returns the new value.
+func (x *WatchableFloat64) Add(delta float64) (new float64) {
+	new = x.v.Add(delta)
+	x.notify()
+	return new
+}
+
+// Swap atomically stores new into x, wakes watchers and returns the previous
+// value.
+func (x *WatchableFloat64) Swap(new float64) (old float64) {
+	old = x.v.Swap(new)
+	x.notify()
+	return old
+}
+
+// CompareAndSwap executes the compare-and-swap operation for x and wakes
+// watchers if the value was swapped.
+func (x *WatchableFloat64) CompareAndSwap(old, new float64) (swapped bool) {
+	if swapped = x.v.CompareAndSwap(old, new); swapped {
+		x.notify()
+	}
+	return swapped
+}
+
+// notify wakes waiters and sends the current value to subscribers. The value
+// is loaded with mu held rather than passed in by the caller, so that when
+// several writers race the last notification carries the last value, not
+// that of whichever writer happened to take the lock last.
+func (x *WatchableFloat64) notify() {
+	if x.watchers.Load() == 0 {
+		return
+	}
+	x.mu.Lock()
+	if x.changed != nil {
+		close(x.changed)
+		x.changed = nil
+	}
+	if len(x.subs) != 0 {
+		v := x.v.Load()
+		for c := range x.subs {
+			sendLatest(c, v)
+		}
+	}
+	x.mu.Unlock()
+}
+
+// sendLatest sends v on c, which has a buffer of one, replacing a value the
+// receiver has not picked up yet. It must be called with mu held.
+func sendLatest(c chan float64, v float64) {
+	select {
+	case <-c:
+	default:
+	}
+	c <- v
+}
+
+// WaitUntil blocks until pred returns true for the value of x, and returns
+// that value. pred is called with the current value first and then after
+// every change, possibly skipping values that changed in quick succession.
+// If ctx is done first WaitUntil returns the last value seen and ctx.Err().
+func (x *WatchableFloat64) WaitUntil(ctx context.Context, pred func(float64) bool) (float64, error) {
+	x.watchers.Add(1)
+	defer x.watchers.Add(-1)
+	for {
+		// Take the channel before loading, so a change made after the
+		// load closes it.
+		x.mu.Lock()
+		if x.changed == nil {
+			x.changed = make(chan struct{})
+		}
+		changed := x.changed
+		x.mu.Unlock()
+
+		v := x.v.Load()
+		if pred(v) {
+			return v, nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return v, ctx.Err()
+		}
+	}
+}
+
+// Changes returns a channel that receives the current value and then the new
+// value after every change, and a function that ends the subscription and
+// closes the channel. A slow receiver only sees the latest value;
+// intermediate values are dropped. Every subscription holds on to x and
+// slows down its modifications until stop is called, so stop must be called
+// once the channel is no longer read; calling it again does nothing.
+func (x *WatchableFloat64) Changes() (c <-chan float64, stop func()) {
+	ch := make(chan float64, 1)
+	x.watchers.Add(1)
+	x.mu.Lock()
+	if x.subs == nil {
+		x.subs = make(map[chan float64]struct{})
+	}
+	x.subs[ch] = struct{}{}
+	sendLatest(ch, x.v.Load())
+	x.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			x.mu.Lock()
+			delete(x.subs, ch)
+			close(ch)
+			x.mu.Unlock()
+			x.watchers.Add(-1)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package atomic_float
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestWatchableFloat64(t *testing.T) {
+	var w WatchableFloat64
+	w.Store(1)
+	if result := w.Add(1.5); result != 2.5 {
+		t.Errorf("Expected %v, got %v", 2.5, result)
+	}
+	if result := w.Swap(4); result != 2.5 {
+		t.Errorf("Expected %v, got %v", 2.5, result)
+	}
+	if result := w.CompareAndSwap(4, 5); result != true {
+		t.Errorf("Expected %v, got %v", true, result)
+	}
+	if result := w.Load(); result != 5 {
+		t.Errorf("Expected %v, got %v", 5, result)
+	}
+}
+
+func TestWaitUntil(t *testing.T) {
+	var w WatchableFloat64
+	w.Store(0.5)
+	if v, err := w.WaitUntil(context.Background(), func(v float64) bool { return v > 0 }); err != nil || v != 0.5 {
+		t.Errorf("Expected immediate %v, got %v, %v", 0.5, v, err)
+	}
+
+	const workers = 10
+	const steps = 1000
+	done := make(chan float64)
+	go func() {
+		v, err := w.WaitUntil(context.Background(), func(v float64) bool { return v >= workers*steps })
+		if err != nil {
+			t.Error(err)
+		}
+		done <- v
+	}()
+	w.Store(0)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for j := 0; j < steps; j++ {
+				w.Add(1)
+			}
+		}()
+	}
+	select {
+	case v := <-done:
+		if v != workers*steps {
+			t.Errorf("Expected %v, got %v", workers*steps, v)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("WaitUntil missed the threshold crossing, value is %v", w.Load())
+	}
+}
+
+func TestWaitUntilCanceled(t *testing.T) {
+	var w WatchableFloat64
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	v, err := w.WaitUntil(ctx, func(v float64) bool { return v > 1 })
+	if err != context.DeadlineExceeded || v != 0 {
+		t.Errorf("Expected %v, %v, got %v, %v", 0, context.DeadlineExceeded, v, err)
+	}
+	if result := w.watchers.Load(); result != 0 {
+		t.Errorf("Expected no watchers left, got %v", result)
+	}
+}
+
+func TestChanges(t *testing.T) {
+	var w WatchableFloat64
+	w.Store(1)
+	c, stop := w.Changes()
+	if v := <-c; v != 1 {
+		t.Errorf("Expected current value %v, got %v", 1, v)
+	}
+	w.Add(1)
+	if v := <-c; v != 2 {
+		t.Errorf("Expected %v, got %v", 2, v)
+	}
+
+	// A slow receiver only sees the latest value.
+	w.Store(3)
+	w.Store(4)
+	w.CompareAndSwap(0, 5)
+	if v := <-c; v != 4 {
+		t.Errorf("Expected %v, got %v", 4, v)
+	}
+
+	stop()
+	for range c {
+	}
+	if result := w.watchers.Load(); result != 0 {
+		t.Errorf("Expected no watchers left, got %v", result)
+	}
+	w.Store(6)
+	stop()
+}
+
+// TestChangesStop checks that stopped subscriptions leave nothing behind: no
+// goroutine, no subscriber and no watcher slowing down modifications.
+func TestChangesStop(t *testing.T) {
+	var w WatchableFloat64
+	before := runtime.NumGoroutine()
+	stops := make([]func(), 100)
+	for i := range stops {
+		_, stops[i] = w.Changes()
+	}
+	if result := runtime.NumGoroutine(); result != before {
+		t.Errorf("Expected %v goroutines, got %v", before, result)
+	}
+	for _, stop := range stops {
+		stop()
+	}
+	w.Add(1)
+	if result := w.watchers.Load(); result != 0 {
+		t.Errorf("Expected no watchers left, got %v", result)
+	}
+	if result := len(w.subs); result != 0 {
+		t.Errorf("Expected no subscribers left, got %v", result)
+	}
+}
+
+// TestChangesConcurrentWriters checks that once concurrent writers are done,
+// the last value a subscriber receives is the final value of x and not that
+// of a writer whose notification ran late.
+func TestChangesConcurrentWriters(t *testing.T) {
+	const goroutines = 4
+	const rounds = 50
+	for round := 0; round < rounds; round++ {
+		var w WatchableFloat64
+		c, stop := w.Changes()
+		done := make(chan bool)
+		for i := 0; i < goroutines; i++ {
+			go func(i int) {
+				for j := 0; j < 100; j++ {
+					if j%2 == 0 {
+						w.Store(float64(i*1000 + j))
+					} else {
+						w.Add(1)
+					}
+				}
+				done <- true
+			}(i)
+		}
+		for i := 0; i < goroutines; i++ {
+			<-done
+		}
+		if v, want := <-c, w.Load(); v != want {
+			t.Errorf("Round %d: expected final notification %v, got %v", round, want, v)
+		}
+		stop()
+	}
+}